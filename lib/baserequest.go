@@ -13,6 +13,7 @@ package elastigo
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -22,6 +23,12 @@ import (
 )
 
 func (c *Conn) DoCommand(method string, url string, args map[string]interface{}, data interface{}) ([]byte, error) {
+	return c.DoCommandContext(context.Background(), method, url, args, data)
+}
+
+// DoCommandContext is DoCommand with a context, cancelling it aborts the
+// request
+func (c *Conn) DoCommandContext(ctx context.Context, method string, url string, args map[string]interface{}, data interface{}) ([]byte, error) {
 	var response map[string]interface{}
 	var body []byte
 	var httpStatusCode int
@@ -75,6 +82,7 @@ func (c *Conn) DoCommand(method string, url string, args map[string]interface{},
 		c.RequestTracer(req.Method, req.URL.String(), rbody)
 	}
 
+	req.Request = req.Request.WithContext(ctx)
 	httpStatusCode, body, err = req.Do(&response)
 	if err != nil {
 		return body, err
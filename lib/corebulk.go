@@ -39,6 +39,50 @@ type ErrorBuffer struct {
 	Buf *bytes.Buffer
 }
 
+// Why a buffer was flushed
+const (
+	flushSize = iota
+	flushCount
+	flushTimer
+	flushManual
+)
+
+// A snapshot of the running totals of a BulkIndexer
+type BulkIndexerStats struct {
+	// Docs handed to Index/Update/Delete and LoadNDJSON, counted when queued
+	DocsEnqueued uint64
+	// Docs in batches that were sent successfully
+	DocsFlushed uint64
+	// Batches sent successfully, and their size in bytes
+	BatchesSent uint64
+	BytesSent   uint64
+	// Batches that failed to send, after any retry
+	BatchesFailed uint64
+	// Items the bulk response reported as failed
+	ItemsFailed uint64
+	// Number of batches re-sent after a failure
+	Retries uint64
+	// Average time a Sender call takes
+	AvgBatchLatency time.Duration
+
+	// Number of flushes by what triggered them, hitting BulkMaxBuffer,
+	// BulkMaxDocs, BufferDelayMax or a call to Flush (including Stop)
+	FlushedBySize   uint64
+	FlushedByCount  uint64
+	FlushedByTimer  uint64
+	FlushedManually uint64
+
+	// Time of the last successful send, zero if there has been none
+	LastFlush time.Time
+}
+
+// A buffer on its way to the http sender
+type bulkBatch struct {
+	buf  *bytes.Buffer
+	docs int
+	size int
+}
+
 // A bulk indexer creates goroutines, and channels for connecting and sending data
 // to elasticsearch in bulk, using buffers.
 type BulkIndexer struct {
@@ -53,6 +97,12 @@ type BulkIndexer struct {
 	// operation has occurred
 	Refresh bool
 
+	// Coalesce keeps only the last action per _index/_type/_id in the pending
+	// buffer, partial doc updates are merged into the pending index or update
+	// of the same document where possible.  Actions without an id, and
+	// creates, which fail if the doc exists, are kept after what is pending.
+	Coalesce bool
+
 	// If we encounter an error in sending, we are going to retry for this long
 	// before returning an error
 	// if 0 it will not retry
@@ -73,7 +123,7 @@ type BulkIndexer struct {
 	timerDoneChan chan struct{}
 
 	// Channel to send a complete byte.Buffer to the http sendor
-	sendBuf chan *bytes.Buffer
+	sendBuf chan *bulkBatch
 	// byte buffer for docs that have been converted to bytes, but not yet sent
 	buf *bytes.Buffer
 	// Buffer for Max number of time before forcing flush
@@ -90,10 +140,18 @@ type BulkIndexer struct {
 	// If we are indexing enough docs per bufferdelaymax, we won't need to do time
 	// based eviction, else we do.
 	needsTimeBasedFlush bool
+	// Docs in buf, by position, only tracked when Coalesce is set
+	pending []*bulkDoc
 	// Lock for document writes/operations
 	mu sync.Mutex
 	// Wait Group for the http sends
 	sendWg *sync.WaitGroup
+
+	// Lock for stats, and the total time spent in Sender to average it
+	statsMu      sync.Mutex
+	stats        BulkIndexerStats
+	sendDuration time.Duration
+	sendCalls    int64
 }
 
 func (b *BulkIndexer) NumErrors() uint64 {
@@ -101,7 +159,7 @@ func (b *BulkIndexer) NumErrors() uint64 {
 }
 
 func (c *Conn) NewBulkIndexer(maxConns int) *BulkIndexer {
-	b := BulkIndexer{conn: c, sendBuf: make(chan *bytes.Buffer, maxConns)}
+	b := BulkIndexer{conn: c, sendBuf: make(chan *bulkBatch, maxConns)}
 	b.needsTimeBasedFlush = true
 	b.buf = new(bytes.Buffer)
 	b.maxConns = maxConns
@@ -162,14 +220,27 @@ func (b *BulkIndexer) Stop() {
 }
 
 func (b *BulkIndexer) PendingDocuments() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
 	return b.docCt
 }
 
+// Stats returns a snapshot of the counters for this indexer
+func (b *BulkIndexer) Stats() BulkIndexerStats {
+	b.statsMu.Lock()
+	defer b.statsMu.Unlock()
+	stats := b.stats
+	if b.sendCalls > 0 {
+		stats.AvgBatchLatency = b.sendDuration / time.Duration(b.sendCalls)
+	}
+	return stats
+}
+
 // Flush all current documents to ElasticSearch
 func (b *BulkIndexer) Flush() {
 	b.mu.Lock()
 	if b.docCt > 0 {
-		b.send(b.buf)
+		b.send(b.buf, flushManual)
 	}
 	b.mu.Unlock()
 }
@@ -183,10 +254,11 @@ func (b *BulkIndexer) startHttpSender() {
 	for i := 0; i < b.maxConns; i++ {
 		b.sendWg.Add(1)
 		go func() {
-			for buf := range b.sendBuf {
+			for batch := range b.sendBuf {
+				buf := batch.buf
 				// Copy for the potential re-send.
 				bufCopy := bytes.NewBuffer(buf.Bytes())
-				err := b.Sender(buf)
+				err := b.timedSend(buf)
 
 				// Perhaps a b.FailureStrategy(err)  ??  with different types of strategies
 				//  1.  Retry, then panic
@@ -196,15 +268,24 @@ func (b *BulkIndexer) startHttpSender() {
 					buf = bytes.NewBuffer(bufCopy.Bytes())
 					if b.RetryForSeconds > 0 {
 						time.Sleep(time.Second * time.Duration(b.RetryForSeconds))
-						err = b.Sender(bufCopy)
+						b.statsMu.Lock()
+						b.stats.Retries++
+						b.statsMu.Unlock()
+						err = b.timedSend(bufCopy)
 						if err == nil {
 							// Successfully re-sent with no error
+							b.batchSent(batch)
 							continue
 						}
 					}
+					b.statsMu.Lock()
+					b.stats.BatchesFailed++
+					b.statsMu.Unlock()
 					if b.ErrorChannel != nil {
 						b.ErrorChannel <- &ErrorBuffer{err, buf}
 					}
+				} else {
+					b.batchSent(batch)
 				}
 			}
 			b.sendWg.Done()
@@ -226,7 +307,7 @@ func (b *BulkIndexer) startTimer() {
 				// where time isn't needed
 				if b.buf.Len() > 0 && b.needsTimeBasedFlush {
 					b.needsTimeBasedFlush = true
-					b.send(b.buf)
+					b.send(b.buf, flushTimer)
 				} else if b.buf.Len() > 0 {
 					b.needsTimeBasedFlush = true
 				}
@@ -247,24 +328,64 @@ func (b *BulkIndexer) startDocChannel() {
 	go func() {
 		for docBytes := range b.bulkChannel {
 			b.mu.Lock()
-			b.docCt += 1
-			b.buf.Write(docBytes)
-			if b.buf.Len() >= b.BulkMaxBuffer || b.docCt >= b.BulkMaxDocs {
+			if b.Coalesce {
+				b.coalesce(docBytes)
+			} else {
+				b.docCt += 1
+				b.buf.Write(docBytes)
+			}
+			if b.buf.Len() >= b.BulkMaxBuffer {
 				b.needsTimeBasedFlush = false
 				//log.Printf("Send due to size:  docs=%d  bufsize=%d", b.docCt, b.buf.Len())
-				b.send(b.buf)
+				b.send(b.buf, flushSize)
+			} else if b.docCt >= b.BulkMaxDocs {
+				b.needsTimeBasedFlush = false
+				b.send(b.buf, flushCount)
 			}
 			b.mu.Unlock()
 		}
 	}()
 }
 
-func (b *BulkIndexer) send(buf *bytes.Buffer) {
+func (b *BulkIndexer) send(buf *bytes.Buffer, reason int) {
+	b.statsMu.Lock()
+	switch reason {
+	case flushSize:
+		b.stats.FlushedBySize++
+	case flushCount:
+		b.stats.FlushedByCount++
+	case flushTimer:
+		b.stats.FlushedByTimer++
+	default:
+		b.stats.FlushedManually++
+	}
+	b.statsMu.Unlock()
 	//b2 := *b.buf
-	b.sendBuf <- buf
+	b.sendBuf <- &bulkBatch{buf: buf, docs: b.docCt, size: buf.Len()}
 	b.buf = new(bytes.Buffer)
 	//	b.buf.Reset()
 	b.docCt = 0
+	b.pending = nil
+}
+
+// Call the Sender, keeping track of how long it takes
+func (b *BulkIndexer) timedSend(buf *bytes.Buffer) error {
+	start := time.Now()
+	err := b.Sender(buf)
+	b.statsMu.Lock()
+	b.sendDuration += time.Since(start)
+	b.sendCalls++
+	b.statsMu.Unlock()
+	return err
+}
+
+func (b *BulkIndexer) batchSent(batch *bulkBatch) {
+	b.statsMu.Lock()
+	b.stats.BatchesSent++
+	b.stats.DocsFlushed += uint64(batch.docs)
+	b.stats.BytesSent += uint64(batch.size)
+	b.stats.LastFlush = time.Now()
+	b.statsMu.Unlock()
 }
 
 func (b *BulkIndexer) shutdown() {
@@ -284,7 +405,7 @@ func (b *BulkIndexer) Index(index string, _type string, id, parent, ttl string,
 	if err != nil {
 		return err
 	}
-	b.bulkChannel <- by
+	b.enqueue(by)
 	return nil
 }
 
@@ -294,13 +415,13 @@ func (b *BulkIndexer) Update(index string, _type string, id, parent, ttl string,
 	if err != nil {
 		return err
 	}
-	b.bulkChannel <- by
+	b.enqueue(by)
 	return nil
 }
 
 func (b *BulkIndexer) Delete(index, _type, id string) {
 	queryLine := fmt.Sprintf("{\"delete\":{\"_index\":%q,\"_type\":%q,\"_id\":%q}}\n", index, _type, id)
-	b.bulkChannel <- []byte(queryLine)
+	b.enqueue([]byte(queryLine))
 	return
 }
 
@@ -322,6 +443,15 @@ func (b *BulkIndexer) UpdateWithPartialDoc(index string, _type string, id, paren
 	return b.Update(index, _type, id, parent, ttl, date, data)
 }
 
+// Count the doc and hand it to the doc channel, which blocks while the
+// buffer is being sent
+func (b *BulkIndexer) enqueue(docBytes []byte) {
+	b.statsMu.Lock()
+	b.stats.DocsEnqueued++
+	b.statsMu.Unlock()
+	b.bulkChannel <- docBytes
+}
+
 // This does the actual send of a buffer, which has already been formatted
 // into bytes of ES formatted bulk data
 func (b *BulkIndexer) Send(buf *bytes.Buffer) error {
@@ -343,6 +473,17 @@ func (b *BulkIndexer) Send(buf *bytes.Buffer) error {
 	jsonErr := json.Unmarshal(body, &response)
 	if jsonErr == nil {
 		if response.Errors {
+			failed := 0
+			for _, item := range response.Items {
+				for _, result := range item {
+					if r, ok := result.(map[string]interface{}); ok && r["error"] != nil {
+						failed++
+					}
+				}
+			}
+			b.statsMu.Lock()
+			b.stats.ItemsFailed += uint64(failed)
+			b.statsMu.Unlock()
 			atomic.AddUint64(&b.numErrors, uint64(len(response.Items)))
 			return fmt.Errorf("Bulk Insertion Error. Failed item count [%d]", len(response.Items))
 		}
@@ -357,43 +498,75 @@ func WriteBulkBytes(op string, index string, _type string, id, parent, ttl strin
 	if op != "index" && op != "update" {
 		return nil, errors.New(fmt.Sprintf("Operation '%s' is not yet supported", op))
 	}
+	return writeBulkBytes(op, index, _type, id, parent, ttl, date, data, false)
+}
+
+// The bulk action and data lines of index, create, update or delete.  With
+// omitEmpty an empty index or type is left out, so that the defaults from the
+// url apply.  Delete has no data line.
+func writeBulkBytes(op string, index string, _type string, id, parent, ttl string, date *time.Time, data interface{}, omitEmpty bool) ([]byte, error) {
+	if op != "index" && op != "create" && op != "update" && op != "delete" {
+		return nil, errors.New(fmt.Sprintf("Operation '%s' is not yet supported", op))
+	}
 
 	// First line
 	buf := bytes.Buffer{}
-	buf.WriteString(fmt.Sprintf(`{"%s":{"_index":"`, op))
-	buf.WriteString(index)
-	buf.WriteString(`","_type":"`)
-	buf.WriteString(_type)
-	buf.WriteString(`"`)
+	buf.WriteString(fmt.Sprintf(`{"%s":{`, op))
+	sep := ""
+	if len(index) > 0 || !omitEmpty {
+		buf.WriteString(`"_index":"`)
+		buf.WriteString(index)
+		buf.WriteString(`"`)
+		sep = ","
+	}
+	if len(_type) > 0 || !omitEmpty {
+		buf.WriteString(sep)
+		buf.WriteString(`"_type":"`)
+		buf.WriteString(_type)
+		buf.WriteString(`"`)
+		sep = ","
+	}
 	if len(id) > 0 {
-		buf.WriteString(`,"_id":"`)
+		buf.WriteString(sep)
+		buf.WriteString(`"_id":"`)
 		buf.WriteString(id)
 		buf.WriteString(`"`)
+		sep = ","
 	}
 
 	if len(parent) > 0 {
-		buf.WriteString(`,"_parent":"`)
+		buf.WriteString(sep)
+		buf.WriteString(`"_parent":"`)
 		buf.WriteString(parent)
 		buf.WriteString(`"`)
+		sep = ","
 	}
 
 	if op == "update" {
-		buf.WriteString(`,"_retry_on_conflict":3`)
+		buf.WriteString(sep)
+		buf.WriteString(`"_retry_on_conflict":3`)
+		sep = ","
 	}
 
 	if len(ttl) > 0 {
-		buf.WriteString(`,"ttl":"`)
+		buf.WriteString(sep)
+		buf.WriteString(`"ttl":"`)
 		buf.WriteString(ttl)
 		buf.WriteString(`"`)
+		sep = ","
 	}
 	if date != nil {
-		buf.WriteString(`,"_timestamp":"`)
+		buf.WriteString(sep)
+		buf.WriteString(`"_timestamp":"`)
 		buf.WriteString(strconv.FormatInt(date.UnixNano()/1e6, 10))
 		buf.WriteString(`"`)
 	}
 
 	buf.WriteString(`}}`)
 	buf.WriteRune('\n')
+	if op == "delete" {
+		return buf.Bytes(), nil
+	}
 	//buf.WriteByte('\n')
 	switch v := data.(type) {
 	case *bytes.Buffer:
@@ -18,8 +18,10 @@ import (
 	"flag"
 	"fmt"
 	"log"
+	"net/http"
 	"net/url"
 	"strconv"
+	"strings"
 	"sync"
 	"testing"
 	"time"
@@ -302,6 +304,267 @@ func TestBulkDelete(t *testing.T) {
 	assert.T(t, asExpected, fmt.Sprintf("Should have sent '%s' but actually sent '%s'", expected, sent))
 }
 
+func TestBulkIndexerStats(t *testing.T) {
+	InitTests(true)
+	c := NewTestConn()
+	indexer := c.NewBulkIndexer(1)
+	indexer.BulkMaxDocs = 2
+	totalBytes := 0
+	indexer.Sender = func(buf *bytes.Buffer) error {
+		totalBytes += buf.Len()
+		return nil
+	}
+	indexer.Start()
+
+	for i := 0; i < 3; i++ {
+		indexer.Index("users", "user", strconv.Itoa(i), "", "", nil, `{"name":"smurfs"}`)
+	}
+	// counted as soon as Index returns
+	assert.Equal(t, uint64(3), indexer.Stats().DocsEnqueued)
+	waitFor(func() bool {
+		return indexer.Stats().FlushedByCount == 1 && indexer.PendingDocuments() == 1
+	}, 5)
+	indexer.Flush()
+	indexer.Stop()
+
+	stats := indexer.Stats()
+	assert.Equal(t, uint64(3), stats.DocsFlushed)
+	assert.Equal(t, uint64(2), stats.BatchesSent)
+	assert.Equal(t, uint64(totalBytes), stats.BytesSent)
+	assert.Equal(t, uint64(1), stats.FlushedByCount)
+	assert.Equal(t, uint64(1), stats.FlushedManually)
+	assert.Equal(t, uint64(0), stats.BatchesFailed)
+	assert.T(t, !stats.LastFlush.IsZero(), "Should have recorded the last flush")
+	assert.Equal(t, 0, indexer.PendingDocuments())
+
+	// item level failures reported by elasticsearch
+	c = setup(t)
+	defer teardown()
+	mux.HandleFunc("/_bulk", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"took":1,"errors":true,"items":[
+			{"index":{"_index":"users","_type":"user","_id":"1","status":201}},
+			{"index":{"_index":"users","_type":"user","_id":"2","status":400,"error":"MapperParsingException[failed to parse]"}}
+		]}`))
+	})
+	indexer = c.NewBulkIndexer(1)
+	indexer.Start()
+	indexer.Index("users", "user", "1", "", "", nil, `{"name":"smurfs"}`)
+	indexer.Index("users", "user", "2", "", "", nil, `{"name":[}`)
+	assert.Equal(t, uint64(2), indexer.Stats().DocsEnqueued)
+	waitFor(func() bool {
+		return indexer.PendingDocuments() == 2
+	}, 5)
+	indexer.Flush()
+	indexer.Stop()
+
+	stats = indexer.Stats()
+	assert.Equal(t, uint64(1), stats.ItemsFailed)
+	assert.Equal(t, uint64(1), stats.BatchesFailed)
+	assert.Equal(t, uint64(0), stats.BatchesSent)
+	assert.T(t, stats.AvgBatchLatency > 0, "Should have timed the send")
+}
+
+func TestBulkCoalesce(t *testing.T) {
+	InitTests(true)
+	var lock sync.Mutex
+	c := NewTestConn()
+	indexer := c.NewBulkIndexer(1)
+	indexer.Coalesce = true
+	sentBytes := []byte{}
+
+	indexer.Sender = func(buf *bytes.Buffer) error {
+		lock.Lock()
+		sentBytes = append(sentBytes, buf.Bytes()...)
+		lock.Unlock()
+		return nil
+	}
+	indexer.Start()
+
+	indexer.Index("fake", "fake_type", "1", "", "", nil, `{"name":"smurfs","age":22,"tags":{"a":1}}`)
+	indexer.Index("fake", "fake_type", "2", "", "", nil, `{"name":"gargamel"}`)
+	indexer.UpdateWithPartialDoc("fake", "fake_type", "1", "", "", nil, map[string]interface{}{"age": 23, "tags": map[string]interface{}{"b": 2}}, false)
+	indexer.UpdateWithPartialDoc("fake", "fake_type", "3", "", "", nil, map[string]interface{}{"age": 1}, false)
+	indexer.UpdateWithPartialDoc("fake", "fake_type", "3", "", "", nil, map[string]interface{}{"name": "azrael"}, true)
+	indexer.UpdateWithWithScript("fake", "fake_type", "3", "", "", nil, "ctx._source.age += 1")
+	indexer.Index("fake", "fake_type", "2", "", "", nil, `{"name":"papa"}`)
+	indexer.Delete("fake", "fake_type", "2")
+	indexer.Index("fake", "fake_type", "", "", "", nil, `{"name":"noid"}`)
+
+	waitFor(func() bool {
+		return indexer.PendingDocuments() == 5
+	}, 5)
+	indexer.Flush()
+	indexer.Stop()
+
+	lock.Lock()
+	sent := string(sentBytes)
+	lock.Unlock()
+	expected := `{"index":{"_index":"fake","_type":"fake_type","_id":"1"}}
+{"age":23,"name":"smurfs","tags":{"a":1,"b":2}}
+{"update":{"_index":"fake","_type":"fake_type","_id":"3","_retry_on_conflict":3}}
+{"doc":{"age":1,"name":"azrael"},"doc_as_upsert":true}
+{"update":{"_index":"fake","_type":"fake_type","_id":"3","_retry_on_conflict":3}}
+{"script":"ctx._source.age += 1"}
+{"delete":{"_index":"fake","_type":"fake_type","_id":"2"}}
+{"index":{"_index":"fake","_type":"fake_type"}}
+{"name":"noid"}
+`
+	assert.T(t, sent == expected, fmt.Sprintf("Should have sent '%s' but actually sent '%s'", expected, sent))
+}
+
+func TestBulkCoalesceCreate(t *testing.T) {
+	InitTests(true)
+	var lock sync.Mutex
+	c := NewTestConn()
+	indexer := c.NewBulkIndexer(1)
+	indexer.Coalesce = true
+	sentBytes := []byte{}
+
+	indexer.Sender = func(buf *bytes.Buffer) error {
+		lock.Lock()
+		sentBytes = append(sentBytes, buf.Bytes()...)
+		lock.Unlock()
+		return nil
+	}
+	indexer.Start()
+
+	// a create depends on the actions before it, so they are kept, and an
+	// update after it is not merged into it
+	input := `{"delete":{"_index":"fake","_type":"fake_type","_id":"1"}}
+{"create":{"_index":"fake","_type":"fake_type","_id":"1"}}
+{"name":"smurfs"}
+{"index":{"_index":"fake","_type":"fake_type","_id":"2"}}
+{"name":"gargamel"}
+{"create":{"_index":"fake","_type":"fake_type","_id":"2"}}
+{"name":"azrael"}
+{"index":{"_index":"fake","_type":"fake_type","_id":"3"}}
+{"name":"papa"}
+{"delete":{"_index":"fake","_type":"fake_type","_id":"3"}}
+{"create":{"_index":"fake","_type":"fake_type","_id":"3"}}
+{"name":"brainy"}
+{"update":{"_index":"fake","_type":"fake_type","_id":"3"}}
+{"doc":{"age":1}}
+`
+	_, err := indexer.LoadNDJSON(strings.NewReader(input), nil)
+	assert.T(t, err == nil, fmt.Sprintf("Should not have any errors %v", err))
+	waitFor(func() bool {
+		return indexer.PendingDocuments() == 7
+	}, 5)
+	indexer.Flush()
+	indexer.Stop()
+
+	lock.Lock()
+	sent := string(sentBytes)
+	lock.Unlock()
+	expected := `{"delete":{"_index":"fake","_type":"fake_type","_id":"1"}}
+{"create":{"_index":"fake","_type":"fake_type","_id":"1"}}
+{"name":"smurfs"}
+{"index":{"_index":"fake","_type":"fake_type","_id":"2"}}
+{"name":"gargamel"}
+{"create":{"_index":"fake","_type":"fake_type","_id":"2"}}
+{"name":"azrael"}
+{"delete":{"_index":"fake","_type":"fake_type","_id":"3"}}
+{"create":{"_index":"fake","_type":"fake_type","_id":"3"}}
+{"name":"brainy"}
+{"update":{"_index":"fake","_type":"fake_type","_id":"3"}}
+{"doc":{"age":1}}
+`
+	assert.T(t, sent == expected, fmt.Sprintf("Should have sent '%s' but actually sent '%s'", expected, sent))
+}
+
+func TestBulkLoadNDJSON(t *testing.T) {
+	InitTests(true)
+	var lock sync.Mutex
+	c := NewTestConn()
+	indexer := c.NewBulkIndexer(1)
+	sentBytes := []byte{}
+
+	indexer.Sender = func(buf *bytes.Buffer) error {
+		lock.Lock()
+		sentBytes = append(sentBytes, buf.Bytes()...)
+		lock.Unlock()
+		return nil
+	}
+	indexer.Start()
+
+	input := `{"request_id": "user-026", "title": "Bulk loader", "meta": {"n": 26}}
+
+{"request_id": "user-027", "title": "UDP bulk", "meta": {"n": 27}}
+`
+	reports := 0
+	progress, err := indexer.LoadNDJSON(strings.NewReader(input), &NDJSONOptions{
+		Index:         "backlog-{meta.n}",
+		Type:          "request",
+		Id:            "{request_id}",
+		ProgressEvery: 1,
+		Progress:      func(NDJSONProgress) { reports++ },
+	})
+	indexer.Flush()
+	indexer.Stop()
+
+	assert.T(t, err == nil, fmt.Sprintf("Should not have any errors %v", err))
+	assert.T(t, progress.Lines == 3 && progress.Docs == 2 && progress.Skipped == 1, fmt.Sprintf("Unexpected progress %+v", progress))
+	assert.T(t, reports == 3, fmt.Sprintf("Should have reported progress 3 times but was %d", reports))
+
+	lock.Lock()
+	sent := string(sentBytes)
+	lock.Unlock()
+	expected := `{"index":{"_index":"backlog-26","_type":"request","_id":"user-026"}}
+{"request_id": "user-026", "title": "Bulk loader", "meta": {"n": 26}}
+{"index":{"_index":"backlog-27","_type":"request","_id":"user-027"}}
+{"request_id": "user-027", "title": "UDP bulk", "meta": {"n": 27}}
+`
+	assert.T(t, sent == expected, fmt.Sprintf("Should have sent '%s' but actually sent '%s'", expected, sent))
+}
+
+func TestBulkLoadNDJSONEscapes(t *testing.T) {
+	line := []byte(`{"id": "say \"hi\" C:\\", "n": 1}`)
+	doc, err := ndjsonDocBytes("index", &NDJSONOptions{Index: "quotes", Id: "{id}-{n}"}, line)
+	assert.T(t, err == nil, fmt.Sprintf("Should not have any errors %v", err))
+
+	action := bytes.SplitN(doc, []byte("\n"), 2)[0]
+	var meta map[string]map[string]string
+	err = json.Unmarshal(action, &meta)
+	assert.T(t, err == nil, fmt.Sprintf("Should have written a valid action line %s: %v", action, err))
+	assert.Equal(t, `say "hi" C:\-1`, meta["index"]["_id"])
+}
+
+func TestBulkLoadNDJSONRaw(t *testing.T) {
+	InitTests(true)
+	var lock sync.Mutex
+	c := NewTestConn()
+	indexer := c.NewBulkIndexer(1)
+	sentBytes := []byte{}
+
+	indexer.Sender = func(buf *bytes.Buffer) error {
+		lock.Lock()
+		sentBytes = append(sentBytes, buf.Bytes()...)
+		lock.Unlock()
+		return nil
+	}
+	indexer.Start()
+
+	input := `{"index":{"_index":"fake","_type":"fake_type","_id":"1"}}
+{"name":"smurfs"}
+{"delete":{"_index":"fake","_type":"fake_type","_id":"2"}}`
+	progress, err := indexer.LoadNDJSON(strings.NewReader(input), nil)
+	assert.T(t, err == nil, fmt.Sprintf("Should not have any errors %v", err))
+	assert.T(t, progress.Docs == 2, fmt.Sprintf("Should have loaded 2 actions but was %d", progress.Docs))
+
+	// an action without its source line fails and queues nothing
+	progress, err = indexer.LoadNDJSON(strings.NewReader(`{"index":{"_index":"fake"}}`), &NDJSONOptions{Format: NDJSONBulk})
+	assert.T(t, err != nil, "Should have failed on an action without a source line")
+	assert.T(t, progress.Docs == 0, fmt.Sprintf("Should have loaded no actions but was %d", progress.Docs))
+
+	indexer.Flush()
+	indexer.Stop()
+
+	lock.Lock()
+	sent := string(sentBytes)
+	lock.Unlock()
+	assert.T(t, sent == input+"\n", fmt.Sprintf("Should have sent '%s' but actually sent '%s'", input, sent))
+}
+
 func XXXTestBulkErrors(t *testing.T) {
 	// lets set a bad port, and hope we get a conn refused error?
 	c := NewTestConn()
@@ -0,0 +1,196 @@
+// Copyright 2013 Matthew Baird
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//     http://www.apache.org/licenses/LICENSE-2.0
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package elastigo
+
+import (
+	"bytes"
+	"encoding/json"
+)
+
+// One action in the pending buffer of a coalescing BulkIndexer
+type bulkDoc struct {
+	// _index/_type/_id, empty when there is no id
+	key    string
+	op     string
+	action []byte
+	source []byte
+}
+
+func parseBulkDoc(docBytes []byte) *bulkDoc {
+	doc := &bulkDoc{action: docBytes}
+	if i := bytes.IndexByte(docBytes, '\n'); i >= 0 && i < len(docBytes)-1 {
+		doc.action, doc.source = docBytes[:i+1], docBytes[i+1:]
+	}
+	var meta map[string]struct {
+		Index string `json:"_index"`
+		Type  string `json:"_type"`
+		Id    string `json:"_id"`
+	}
+	if json.Unmarshal(doc.action, &meta) == nil {
+		for op, m := range meta {
+			doc.op = op
+			if len(m.Id) > 0 {
+				doc.key = m.Index + "/" + m.Type + "/" + m.Id
+			}
+		}
+	}
+	return doc
+}
+
+// Add the doc to the buffer, replacing or merging into pending actions for
+// the same id.  Must be called with the lock held.
+func (b *BulkIndexer) coalesce(docBytes []byte) {
+	doc := parseBulkDoc(docBytes)
+	if doc.key == "" {
+		b.appendPending(doc)
+		return
+	}
+
+	var last *bulkDoc
+	for _, p := range b.pending {
+		if p.key == doc.key {
+			last = p
+		}
+	}
+	if last == nil {
+		b.appendPending(doc)
+		return
+	}
+
+	switch doc.op {
+	case "update":
+		if !mergeBulkUpdate(last, doc) {
+			b.appendPending(doc)
+			return
+		}
+	case "index", "delete":
+		// index and delete make any earlier action on the doc moot
+		kept := make([]*bulkDoc, 0, len(b.pending))
+		for _, p := range b.pending {
+			if p.key != doc.key {
+				kept = append(kept, p)
+			}
+		}
+		b.pending = append(kept, doc)
+	default:
+		// create fails if the doc exists, so it depends on whatever was
+		// pending before it, a delete in particular
+		b.appendPending(doc)
+		return
+	}
+	b.rewritePending()
+}
+
+func (b *BulkIndexer) appendPending(doc *bulkDoc) {
+	b.pending = append(b.pending, doc)
+	b.docCt += 1
+	b.buf.Write(doc.action)
+	b.buf.Write(doc.source)
+}
+
+func (b *BulkIndexer) rewritePending() {
+	b.buf.Reset()
+	for _, p := range b.pending {
+		b.buf.Write(p.action)
+		b.buf.Write(p.source)
+	}
+	b.docCt = len(b.pending)
+}
+
+// Merge a partial doc update into a pending index or partial doc update of the
+// same document, reporting false if they can't be combined (scripts, upserts).
+// A create isn't merged into, if the doc exists the create fails and the
+// update, which would have applied on its own, would be lost with it.
+func mergeBulkUpdate(into, update *bulkDoc) bool {
+	partial, upsert, ok := partialDoc(update.source)
+	if !ok {
+		return false
+	}
+	switch into.op {
+	case "index":
+		var source map[string]interface{}
+		if unmarshalDocFields(into.source, &source) != nil {
+			return false
+		}
+		merged, err := json.Marshal(mergeDocFields(source, partial))
+		if err != nil {
+			return false
+		}
+		into.source = append(merged, '\n')
+	case "update":
+		pending, pendingUpsert, ok := partialDoc(into.source)
+		if !ok {
+			return false
+		}
+		body := map[string]interface{}{"doc": mergeDocFields(pending, partial)}
+		if upsert || pendingUpsert {
+			body["doc_as_upsert"] = true
+		}
+		merged, err := json.Marshal(body)
+		if err != nil {
+			return false
+		}
+		into.source = append(merged, '\n')
+	default:
+		return false
+	}
+	return true
+}
+
+// The doc of an update body that is only a partial doc
+func partialDoc(source []byte) (map[string]interface{}, bool, bool) {
+	var body map[string]json.RawMessage
+	if json.Unmarshal(source, &body) != nil {
+		return nil, false, false
+	}
+	var doc map[string]interface{}
+	upsert := false
+	for key, val := range body {
+		switch key {
+		case "doc":
+			if unmarshalDocFields(val, &doc) != nil {
+				return nil, false, false
+			}
+		case "doc_as_upsert":
+			json.Unmarshal(val, &upsert)
+		case "detect_noop":
+		default:
+			return nil, false, false
+		}
+	}
+	return doc, upsert, doc != nil
+}
+
+// Objects are merged recursively, as elasticsearch does for partial updates,
+// anything else in the partial doc replaces the existing value
+func mergeDocFields(doc, partial map[string]interface{}) map[string]interface{} {
+	if doc == nil {
+		doc = make(map[string]interface{})
+	}
+	for key, val := range partial {
+		existing, isObj := doc[key].(map[string]interface{})
+		update, updateIsObj := val.(map[string]interface{})
+		if isObj && updateIsObj {
+			doc[key] = mergeDocFields(existing, update)
+		} else {
+			doc[key] = val
+		}
+	}
+	return doc
+}
+
+// Keep numbers as they were sent rather than round tripping through float64
+func unmarshalDocFields(data []byte, v interface{}) error {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	return dec.Decode(v)
+}
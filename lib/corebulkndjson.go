@@ -0,0 +1,254 @@
+// Copyright 2013 Matthew Baird
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//     http://www.apache.org/licenses/LICENSE-2.0
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package elastigo
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+)
+
+const (
+	// Detect the input format from the first non blank line
+	NDJSONAuto = iota
+	// Input is already in bulk format, action lines followed by source lines
+	NDJSONBulk
+	// Input is one json document per line
+	NDJSONDocs
+)
+
+// Options for BulkIndexer.LoadNDJSON
+//
+// Index, Type and Id are only used for one document per line input, and may
+// reference fields of each document in braces, dotted paths are followed into
+// nested objects:
+//
+//	opts := &NDJSONOptions{Index: "backlog", Type: "request", Id: "{request_id}"}
+type NDJSONOptions struct {
+	// One of NDJSONAuto, NDJSONBulk, NDJSONDocs
+	Format int
+	// Operation for document input, "index" (default) or "update"
+	Op    string
+	Index string
+	Type  string
+	Id    string
+
+	// Optional callback, called every ProgressEvery documents (default 1000)
+	// and once more when the input is exhausted
+	Progress      func(NDJSONProgress)
+	ProgressEvery int
+}
+
+// Running counts for a LoadNDJSON call
+type NDJSONProgress struct {
+	// Lines read from the input, including blank ones
+	Lines int
+	// Documents (or bulk actions) handed to the indexer
+	Docs int
+	// Blank lines that were ignored
+	Skipped int
+}
+
+// LoadNDJSON reads newline delimited json from r and queues every document
+// on the bulk indexer, which must be started to drain the queue.  Input is
+// either raw bulk format (action and source line pairs, delete actions have
+// no source), or one document per line whose index, type and id are
+// templated from the document via opts.
+func (b *BulkIndexer) LoadNDJSON(r io.Reader, opts *NDJSONOptions) (NDJSONProgress, error) {
+	var progress NDJSONProgress
+	if opts == nil {
+		opts = &NDJSONOptions{}
+	}
+	every := opts.ProgressEvery
+	if every <= 0 {
+		every = 1000
+	}
+	op := opts.Op
+	if op == "" {
+		op = "index"
+	}
+
+	format := opts.Format
+	rdr := bufio.NewReader(r)
+	var action []byte
+	for {
+		line, err := rdr.ReadBytes('\n')
+		if err != nil && err != io.EOF {
+			return progress, err
+		}
+		if len(line) == 0 && err == io.EOF {
+			break
+		}
+		progress.Lines++
+		line = bytes.TrimSpace(line)
+
+		if len(line) == 0 {
+			progress.Skipped++
+		} else {
+			if format == NDJSONAuto {
+				format = detectNDJSONFormat(line)
+			}
+
+			var doc []byte
+			switch {
+			case format == NDJSONDocs:
+				var derr error
+				doc, derr = ndjsonDocBytes(op, opts, line)
+				if derr != nil {
+					return progress, fmt.Errorf("line %d: %v", progress.Lines, derr)
+				}
+			case action == nil:
+				name, perr := bulkActionName(line)
+				if perr != nil {
+					return progress, fmt.Errorf("line %d: %v", progress.Lines, perr)
+				}
+				if name == "delete" {
+					doc = append(line, '\n')
+				} else {
+					action = line
+				}
+			default:
+				doc = make([]byte, 0, len(action)+len(line)+2)
+				doc = append(doc, action...)
+				doc = append(doc, '\n')
+				doc = append(doc, line...)
+				doc = append(doc, '\n')
+				action = nil
+			}
+
+			if doc != nil {
+				b.enqueue(doc)
+				progress.Docs++
+				if opts.Progress != nil && progress.Docs%every == 0 {
+					opts.Progress(progress)
+				}
+			}
+		}
+
+		if err == io.EOF {
+			break
+		}
+	}
+
+	if action != nil {
+		return progress, fmt.Errorf("line %d: bulk action has no source line", progress.Lines)
+	}
+	if opts.Progress != nil {
+		opts.Progress(progress)
+	}
+	return progress, nil
+}
+
+// A line is bulk format if it is an object with a single action key
+func detectNDJSONFormat(line []byte) int {
+	if _, err := bulkActionName(line); err == nil {
+		return NDJSONBulk
+	}
+	return NDJSONDocs
+}
+
+func bulkActionName(line []byte) (string, error) {
+	var meta map[string]json.RawMessage
+	if err := json.Unmarshal(line, &meta); err != nil {
+		return "", err
+	}
+	if len(meta) == 1 {
+		for name, val := range meta {
+			switch name {
+			case "index", "create", "update", "delete":
+				if len(val) > 0 && val[0] == '{' {
+					return name, nil
+				}
+			}
+		}
+	}
+	return "", fmt.Errorf("expected a bulk action line but got %s", line)
+}
+
+func ndjsonDocBytes(op string, opts *NDJSONOptions, line []byte) ([]byte, error) {
+	var doc map[string]interface{}
+	dec := json.NewDecoder(bytes.NewReader(line))
+	dec.UseNumber()
+	if err := dec.Decode(&doc); err != nil {
+		return nil, err
+	}
+	index, err := expandDocTemplate(opts.Index, doc)
+	if err != nil {
+		return nil, err
+	}
+	_type, err := expandDocTemplate(opts.Type, doc)
+	if err != nil {
+		return nil, err
+	}
+	id, err := expandDocTemplate(opts.Id, doc)
+	if err != nil {
+		return nil, err
+	}
+	// the action line is written as is, values from the document can hold
+	// quotes or backslashes
+	index, _type, id = escapeJSONString(index), escapeJSONString(_type), escapeJSONString(id)
+	if op == "update" {
+		return writeBulkBytes(op, index, _type, id, "", "", nil, map[string]interface{}{"doc": json.RawMessage(line)}, true)
+	}
+	return writeBulkBytes(op, index, _type, id, "", "", nil, line, true)
+}
+
+// Replace each {field.path} in tmpl with the value found in doc
+func expandDocTemplate(tmpl string, doc map[string]interface{}) (string, error) {
+	if !strings.Contains(tmpl, "{") {
+		return tmpl, nil
+	}
+	var out bytes.Buffer
+	for {
+		start := strings.Index(tmpl, "{")
+		if start < 0 {
+			out.WriteString(tmpl)
+			return out.String(), nil
+		}
+		end := strings.Index(tmpl[start:], "}")
+		if end < 0 {
+			return "", fmt.Errorf("unterminated field in template %q", tmpl)
+		}
+		out.WriteString(tmpl[:start])
+		path := tmpl[start+1 : start+end]
+
+		var val interface{} = doc
+		for _, key := range strings.Split(path, ".") {
+			obj, ok := val.(map[string]interface{})
+			if !ok {
+				val = nil
+				break
+			}
+			val = obj[key]
+		}
+		switch v := val.(type) {
+		case nil:
+			return "", fmt.Errorf("document has no field %q", path)
+		case string:
+			out.WriteString(v)
+		case map[string]interface{}, []interface{}:
+			return "", fmt.Errorf("field %q is not a scalar", path)
+		default:
+			out.WriteString(fmt.Sprint(v))
+		}
+		tmpl = tmpl[start+end+1:]
+	}
+}
+
+// The contents of s as a json string, without the surrounding quotes
+func escapeJSONString(s string) string {
+	quoted, _ := json.Marshal(s)
+	return string(quoted[1 : len(quoted)-1])
+}
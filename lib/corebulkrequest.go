@@ -0,0 +1,150 @@
+// Copyright 2013 Matthew Baird
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//     http://www.apache.org/licenses/LICENSE-2.0
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package elastigo
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// A single operation of a synchronous Bulk request, see NewBulkIndex,
+// NewBulkCreate, NewBulkUpdate and NewBulkDelete
+type BulkAction struct {
+	// One of index, create, update or delete
+	Op     string
+	Index  string
+	Type   string
+	Id     string
+	Parent string
+	Ttl    string
+	Date   *time.Time
+	// The document for index and create, the update body (doc, script, upsert)
+	// for update, and ignored for delete
+	Data interface{}
+}
+
+func NewBulkIndex(index, _type, id string, data interface{}) BulkAction {
+	return BulkAction{Op: "index", Index: index, Type: _type, Id: id, Data: data}
+}
+
+func NewBulkCreate(index, _type, id string, data interface{}) BulkAction {
+	return BulkAction{Op: "create", Index: index, Type: _type, Id: id, Data: data}
+}
+
+func NewBulkUpdate(index, _type, id string, data interface{}) BulkAction {
+	return BulkAction{Op: "update", Index: index, Type: _type, Id: id, Data: data}
+}
+
+func NewBulkDelete(index, _type, id string) BulkAction {
+	return BulkAction{Op: "delete", Index: index, Type: _type, Id: id}
+}
+
+// The bulk formatted lines for this action
+func (a BulkAction) Bytes() ([]byte, error) {
+	return writeBulkBytes(a.Op, a.Index, a.Type, a.Id, a.Parent, a.Ttl, a.Date, a.Data, true)
+}
+
+type BulkResponse struct {
+	Took   int64              `json:"took"`
+	Errors bool               `json:"errors"`
+	Items  []BulkItemResponse `json:"items"`
+}
+
+// The result of one action, in the same order as the request
+type BulkItemResponse struct {
+	// The action this is the result of, index, create, update or delete
+	Op      string `json:"-"`
+	Index   string `json:"_index"`
+	Type    string `json:"_type"`
+	Id      string `json:"_id"`
+	Version int    `json:"_version"`
+	Status  int    `json:"status"`
+	Found   bool   `json:"found,omitempty"`
+	// Empty unless this action failed
+	Error string `json:"-"`
+}
+
+// Items are keyed by their action, {"index": {"_id": "1", ...}}, and the
+// error is a string on older versions and an object on newer ones
+func (r *BulkItemResponse) UnmarshalJSON(b []byte) error {
+	var wrapper map[string]json.RawMessage
+	if err := json.Unmarshal(b, &wrapper); err != nil {
+		return err
+	}
+	type item BulkItemResponse
+	for op, raw := range wrapper {
+		var it item
+		if err := json.Unmarshal(raw, &it); err != nil {
+			return err
+		}
+		var withErr struct {
+			Error json.RawMessage `json:"error"`
+		}
+		if err := json.Unmarshal(raw, &withErr); err != nil {
+			return err
+		}
+		*r = BulkItemResponse(it)
+		r.Op = op
+		r.Error = esErrorString(withErr.Error)
+	}
+	return nil
+}
+
+// The items that failed
+func (r BulkResponse) Failed() []BulkItemResponse {
+	failed := make([]BulkItemResponse, 0)
+	for _, item := range r.Items {
+		if item.Error != "" {
+			failed = append(failed, item)
+		}
+	}
+	return failed
+}
+
+// Bulk sends all the actions in a single request to /_bulk and waits for the
+// result, unlike the BulkIndexer there is no buffering or background
+// goroutines.  A failure of individual actions is not an error, check
+// BulkResponse.Errors and Failed().  Useful params are refresh, consistency,
+// replication, timeout and routing.
+// http://www.elasticsearch.org/guide/reference/api/bulk.html
+func (c *Conn) Bulk(ctx context.Context, actions []BulkAction, params map[string]interface{}) (BulkResponse, error) {
+	return c.bulk(ctx, "/_bulk", actions, params)
+}
+
+// BulkIndex is Bulk to /{index}/_bulk, actions may leave their Index empty
+// to use it
+func (c *Conn) BulkIndex(ctx context.Context, index string, actions []BulkAction, params map[string]interface{}) (BulkResponse, error) {
+	return c.bulk(ctx, fmt.Sprintf("/%s/_bulk", index), actions, params)
+}
+
+func (c *Conn) bulk(ctx context.Context, url string, actions []BulkAction, params map[string]interface{}) (BulkResponse, error) {
+	var retval BulkResponse
+	buf := new(bytes.Buffer)
+	for i, action := range actions {
+		by, err := action.Bytes()
+		if err != nil {
+			return retval, fmt.Errorf("Bulk action %d: %v", i, err)
+		}
+		buf.Write(by)
+	}
+
+	body, err := c.DoCommandContext(ctx, "POST", url, params, buf)
+	if err != nil {
+		return retval, err
+	}
+	// marshall into json
+	jsonErr := json.Unmarshal(body, &retval)
+	return retval, jsonErr
+}
@@ -0,0 +1,115 @@
+// Copyright 2013 Matthew Baird
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//     http://www.apache.org/licenses/LICENSE-2.0
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package elastigo
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"testing"
+
+	"github.com/bmizerany/assert"
+)
+
+func TestBulkRequest(t *testing.T) {
+	c := setup(t)
+	defer teardown()
+
+	var sent, query string
+	mux.HandleFunc("/users/_bulk", func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		sent = string(body)
+		query = r.URL.RawQuery
+		w.Write([]byte(`{"took":3,"errors":true,"items":[
+			{"index":{"_index":"users","_type":"user","_id":"1","_version":1,"status":201}},
+			{"update":{"_index":"users","_type":"user","_id":"2","status":404,"error":"DocumentMissingException[[users][0] [user][2]: document missing]"}},
+			{"delete":{"_index":"users","_type":"user","_id":"3","_version":2,"status":200,"found":true}},
+			{"create":{"_index":"users","_type":"user","_id":"4","status":409,"error":{"type":"document_already_exists_exception","reason":"[user][4]: document already exists"}}}
+		]}`))
+	})
+
+	actions := []BulkAction{
+		NewBulkIndex("", "user", "1", map[string]interface{}{"name": "smurfs"}),
+		NewBulkUpdate("", "user", "2", map[string]interface{}{"doc": map[string]interface{}{"age": 22}}),
+		NewBulkDelete("", "user", "3"),
+		NewBulkCreate("", "user", "4", `{"name":"gargamel"}`),
+	}
+	response, err := c.BulkIndex(context.Background(), "users", actions, map[string]interface{}{"refresh": true})
+	assert.T(t, err == nil, fmt.Sprintf("Should not have any errors %v", err))
+
+	expected := `{"index":{"_type":"user","_id":"1"}}
+{"name":"smurfs"}
+{"update":{"_type":"user","_id":"2","_retry_on_conflict":3}}
+{"doc":{"age":22}}
+{"delete":{"_type":"user","_id":"3"}}
+{"create":{"_type":"user","_id":"4"}}
+{"name":"gargamel"}
+`
+	assert.Equal(t, expected, sent)
+	assert.Equal(t, "refresh=true", query)
+
+	assert.T(t, response.Errors, "Should have reported errors")
+	assert.Equal(t, 4, len(response.Items))
+	assert.Equal(t, "index", response.Items[0].Op)
+	assert.Equal(t, 201, response.Items[0].Status)
+	assert.Equal(t, "delete", response.Items[2].Op)
+	assert.T(t, response.Items[2].Found, "Should have found the deleted doc")
+
+	failed := response.Failed()
+	assert.Equal(t, 2, len(failed))
+	assert.Equal(t, "2", failed[0].Id)
+	assert.Equal(t, "DocumentMissingException[[users][0] [user][2]: document missing]", failed[0].Error)
+	assert.Equal(t, "document_already_exists_exception: [user][4]: document already exists", failed[1].Error)
+
+	_, err = c.Bulk(context.Background(), []BulkAction{{Op: "upsert"}}, nil)
+	assert.T(t, err != nil, "Should have rejected an unknown operation")
+}
+
+func TestBulkRequestContext(t *testing.T) {
+	c := setup(t)
+	defer teardown()
+
+	var path string
+	mux.HandleFunc("/_bulk", func(w http.ResponseWriter, r *http.Request) {
+		path = r.URL.Path
+		w.Write([]byte(`{"took":1,"errors":false,"items":[{"index":{"_index":"users","_type":"user","_id":"1","status":201}}]}`))
+	})
+
+	actions := []BulkAction{NewBulkIndex("users", "user", "1", `{"name":"smurfs"}`)}
+	response, err := c.Bulk(context.Background(), actions, nil)
+	assert.T(t, err == nil, fmt.Sprintf("Should not have any errors %v", err))
+	assert.Equal(t, "/_bulk", path)
+	assert.Equal(t, 1, len(response.Items))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	path = ""
+	_, err = c.Bulk(ctx, actions, nil)
+	assert.T(t, err != nil, "Should have failed with a cancelled context")
+	assert.Equal(t, "", path)
+}
+
+// WriteBulkBytes keeps writing an empty index and type, and only knows index
+// and update, for callers that predate Conn.Bulk
+func TestWriteBulkBytes(t *testing.T) {
+	by, err := WriteBulkBytes("index", "", "", "1", "", "", nil, `{"name":"smurfs"}`)
+	assert.T(t, err == nil, fmt.Sprintf("Should not have any errors %v", err))
+	assert.Equal(t, "{\"index\":{\"_index\":\"\",\"_type\":\"\",\"_id\":\"1\"}}\n{\"name\":\"smurfs\"}\n", string(by))
+
+	by, err = WriteBulkBytes("update", "users", "user", "2", "", "", nil, `{"doc":{}}`)
+	assert.T(t, err == nil, fmt.Sprintf("Should not have any errors %v", err))
+	assert.Equal(t, "{\"update\":{\"_index\":\"users\",\"_type\":\"user\",\"_id\":\"2\",\"_retry_on_conflict\":3}}\n{\"doc\":{}}\n", string(by))
+
+	_, err = WriteBulkBytes("delete", "users", "user", "3", "", "", nil, nil)
+	assert.T(t, err != nil, "Should not support delete")
+}
@@ -10,3 +10,175 @@
 // limitations under the License.
 
 package elastigo
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"io"
+	"net"
+	"sync"
+	"sync/atomic"
+)
+
+const (
+	// Largest payload that fits in a single ipv4 udp datagram
+	BulkUDPMaxDatagram = 65507
+	// Default port of the elasticsearch bulk udp service (bulk.udp.port)
+	BulkUDPPort = "9700"
+)
+
+// A BulkUDPSender ships bulk formatted buffers to the elasticsearch bulk udp
+// service.  It is fire and forget, nothing is acknowledged, so it is suited to
+// metrics style data where losing a few docs is acceptable.  Use it as the
+// Sender of a BulkIndexer:
+//
+//	udp, err := NewBulkUDPSender("localhost:9700")
+//	indexer := conn.NewBulkIndexer(1)
+//	indexer.Sender = udp.Send
+//
+// Buffers are split on action boundaries into datagrams of at most
+// MaxDatagram bytes, an action that alone exceeds that is dropped.
+type BulkUDPSender struct {
+	// Max bytes per datagram, the bulk udp service treats each datagram as
+	// a complete bulk request
+	MaxDatagram int
+
+	conn net.Conn
+
+	numDatagrams uint64
+	numDocs      uint64
+	numBytes     uint64
+	numDropped   uint64
+
+	mu     sync.Mutex
+	closed bool
+}
+
+// Create a udp sender for the given host:port, if no port is given the default
+// BulkUDPPort is used
+func NewBulkUDPSender(addr string) (*BulkUDPSender, error) {
+	if _, _, err := net.SplitHostPort(addr); err != nil {
+		addr = net.JoinHostPort(addr, BulkUDPPort)
+	}
+	conn, err := net.Dial("udp", addr)
+	if err != nil {
+		return nil, err
+	}
+	return &BulkUDPSender{MaxDatagram: BulkUDPMaxDatagram, conn: conn}, nil
+}
+
+// Send the bulk formatted buffer as one or more datagrams.  Docs that can't be
+// sent are counted in NumDropped rather than returned as an error, as a retry
+// would resend the datagrams that did go out, the only error is sending on a
+// closed sender.
+func (s *BulkUDPSender) Send(buf *bytes.Buffer) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return errors.New("Bulk udp sender is closed")
+	}
+
+	max := s.MaxDatagram
+	if max <= 0 {
+		max = BulkUDPMaxDatagram
+	}
+
+	var datagram bytes.Buffer
+	docs := 0
+	flush := func() {
+		if docs == 0 {
+			return
+		}
+		if _, err := s.conn.Write(datagram.Bytes()); err != nil {
+			atomic.AddUint64(&s.numDropped, uint64(docs))
+		} else {
+			atomic.AddUint64(&s.numDatagrams, 1)
+			atomic.AddUint64(&s.numDocs, uint64(docs))
+			atomic.AddUint64(&s.numBytes, uint64(datagram.Len()))
+		}
+		datagram.Reset()
+		docs = 0
+	}
+
+	rdr := bufio.NewReader(buf)
+	for {
+		action, err := readBulkAction(rdr)
+		if len(action) > 0 {
+			if len(action) > max {
+				atomic.AddUint64(&s.numDropped, 1)
+			} else {
+				if datagram.Len()+len(action) > max {
+					flush()
+				}
+				datagram.Write(action)
+				docs++
+			}
+		}
+		if err != nil {
+			break
+		}
+	}
+	flush()
+	return nil
+}
+
+// Read one action, its action line plus the source line unless it is a delete
+func readBulkAction(rdr *bufio.Reader) ([]byte, error) {
+	var action []byte
+	for len(action) == 0 {
+		line, err := rdr.ReadBytes('\n')
+		if len(bytes.TrimSpace(line)) > 0 {
+			action = line
+		}
+		if err != nil {
+			return terminateLine(action), err
+		}
+	}
+	if name, err := bulkActionName(bytes.TrimSpace(action)); err == nil && name == "delete" {
+		return action, nil
+	}
+	source, err := rdr.ReadBytes('\n')
+	if err == io.EOF && len(source) > 0 {
+		err = nil
+	}
+	return terminateLine(append(action, source...)), err
+}
+
+func terminateLine(b []byte) []byte {
+	if len(b) > 0 && b[len(b)-1] != '\n' {
+		b = append(b, '\n')
+	}
+	return b
+}
+
+// Number of datagrams written
+func (s *BulkUDPSender) NumDatagrams() uint64 {
+	return atomic.LoadUint64(&s.numDatagrams)
+}
+
+// Number of docs (bulk actions) written
+func (s *BulkUDPSender) NumDocs() uint64 {
+	return atomic.LoadUint64(&s.numDocs)
+}
+
+// Number of payload bytes written
+func (s *BulkUDPSender) NumBytes() uint64 {
+	return atomic.LoadUint64(&s.numBytes)
+}
+
+// Number of docs dropped, either too large for a datagram or lost to a
+// failed write
+func (s *BulkUDPSender) NumDropped() uint64 {
+	return atomic.LoadUint64(&s.numDropped)
+}
+
+func (s *BulkUDPSender) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return nil
+	}
+	s.closed = true
+	return s.conn.Close()
+}
@@ -0,0 +1,68 @@
+// Copyright 2013 Matthew Baird
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//     http://www.apache.org/licenses/LICENSE-2.0
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package elastigo
+
+import (
+	"bytes"
+	"fmt"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bmizerany/assert"
+)
+
+func TestBulkUDPSender(t *testing.T) {
+	listener, err := net.ListenPacket("udp", "127.0.0.1:0")
+	assert.T(t, err == nil, fmt.Sprintf("Should have opened a udp listener %v", err))
+	defer listener.Close()
+
+	sender, err := NewBulkUDPSender(listener.LocalAddr().String())
+	assert.T(t, err == nil, fmt.Sprintf("Should have created the sender %v", err))
+	defer sender.Close()
+
+	first, _ := WriteBulkBytes("index", "metrics", "cpu", "1", "", "", nil, `{"load":1}`)
+	second, _ := WriteBulkBytes("index", "metrics", "cpu", "2", "", "", nil, `{"load":2}`)
+	huge, _ := WriteBulkBytes("index", "metrics", "cpu", "3", "", "", nil, `{"load":"`+strings.Repeat("x", 200)+`"}`)
+	del := []byte(`{"delete":{"_index":"metrics","_type":"cpu","_id":"4"}}` + "\n")
+
+	// room for two small actions per datagram, but not the huge one
+	sender.MaxDatagram = len(first) + len(second)
+
+	buf := new(bytes.Buffer)
+	buf.Write(first)
+	buf.Write(second)
+	buf.Write(huge)
+	buf.Write(del)
+	err = sender.Send(buf)
+	assert.T(t, err == nil, fmt.Sprintf("Should not have any errors %v", err))
+
+	datagrams := []string{}
+	packet := make([]byte, BulkUDPMaxDatagram)
+	for i := 0; i < 2; i++ {
+		listener.SetReadDeadline(time.Now().Add(time.Second))
+		n, _, err := listener.ReadFrom(packet)
+		assert.T(t, err == nil, fmt.Sprintf("Should have read a datagram %v", err))
+		datagrams = append(datagrams, string(packet[:n]))
+	}
+
+	assert.Equal(t, string(first)+string(second), datagrams[0])
+	assert.Equal(t, string(del), datagrams[1])
+	assert.Equal(t, uint64(2), sender.NumDatagrams())
+	assert.Equal(t, uint64(3), sender.NumDocs())
+	assert.Equal(t, uint64(1), sender.NumDropped())
+	assert.Equal(t, uint64(len(first)+len(second)+len(del)), sender.NumBytes())
+
+	sender.Close()
+	assert.T(t, sender.Send(new(bytes.Buffer)) != nil, "Should fail to send once closed")
+}
@@ -10,3 +10,121 @@
 // limitations under the License.
 
 package elastigo
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// MultiSearch runs the searches in a single request, returning their results
+// in the same order.  A search failing doesn't fail the others, its result
+// has the Error set instead.  The index, types, search type, preference and
+// routing of each SearchDsl go in its header, its from, size, fields and
+// _source in its body, and any other args of it are ignored.  Useful args
+// for the whole request are search_type and max_concurrent_searches.
+// http://www.elastic.co/guide/en/elasticsearch/reference/1.x/search-multi-search.html
+func (c *Conn) MultiSearch(ctx context.Context, searches []*SearchDsl, args map[string]interface{}) ([]SearchResult, error) {
+	buf := new(bytes.Buffer)
+	for i, s := range searches {
+		if err := s.writeMultiSearch(buf); err != nil {
+			return nil, fmt.Errorf("MultiSearch search %d: %v", i, err)
+		}
+	}
+
+	body, err := c.DoCommandContext(ctx, "POST", "/_msearch", args, buf)
+	if err != nil {
+		return nil, err
+	}
+	var msearch struct {
+		Responses []json.RawMessage `json:"responses"`
+	}
+	if err := json.Unmarshal(body, &msearch); err != nil {
+		return nil, err
+	}
+	if len(msearch.Responses) != len(searches) {
+		return nil, fmt.Errorf("MultiSearch sent %d searches but got %d responses", len(searches), len(msearch.Responses))
+	}
+
+	results := make([]SearchResult, len(msearch.Responses))
+	for i, raw := range msearch.Responses {
+		var failed struct {
+			Error json.RawMessage `json:"error"`
+		}
+		if err := json.Unmarshal(raw, &failed); err != nil {
+			return nil, err
+		}
+		if results[i].Error = esErrorString(failed.Error); results[i].Error != "" {
+			continue
+		}
+		if err := json.Unmarshal(raw, &results[i]); err != nil {
+			return nil, err
+		}
+		results[i].RawJSON = raw
+	}
+	return results, nil
+}
+
+// Write the header and body lines of the search
+func (s *SearchDsl) writeMultiSearch(buf *bytes.Buffer) error {
+	if err := validateBucketsPaths(s.AggregatesVal); err != nil {
+		return err
+	}
+
+	header := make(map[string]interface{})
+	if len(s.Index) > 0 {
+		header["index"] = s.Index
+	}
+	if len(s.types) > 0 {
+		header["type"] = strings.Join(s.types, ",")
+	}
+	for _, key := range []string{"search_type", "preference", "routing"} {
+		if val, ok := s.args[key]; ok {
+			header[key] = val
+		}
+	}
+
+	bodyBytes, err := json.Marshal(s)
+	if err != nil {
+		return err
+	}
+	var body map[string]interface{}
+	if err := json.Unmarshal(bodyBytes, &body); err != nil {
+		return err
+	}
+	// the args that are url params of a single search
+	for _, key := range []string{"from", "size"} {
+		if val, ok := s.args[key]; ok {
+			n, err := strconv.Atoi(fmt.Sprint(val))
+			if err != nil {
+				return fmt.Errorf("invalid %s %q", key, val)
+			}
+			body[key] = n
+		}
+	}
+	if fields, ok := s.args["fields"].(string); ok {
+		body["fields"] = strings.Split(fields, ",")
+	}
+	switch source := s.args["_source"].(type) {
+	case string:
+		body["_source"] = source == "true"
+	case []string:
+		body["_source"] = source
+	}
+
+	headerBytes, err := json.Marshal(header)
+	if err != nil {
+		return err
+	}
+	if bodyBytes, err = json.Marshal(body); err != nil {
+		return err
+	}
+	buf.Write(headerBytes)
+	buf.WriteByte('\n')
+	buf.Write(bodyBytes)
+	buf.WriteByte('\n')
+	return nil
+}
@@ -0,0 +1,85 @@
+// Copyright 2013 Matthew Baird
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//     http://www.apache.org/licenses/LICENSE-2.0
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package elastigo
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/bmizerany/assert"
+)
+
+func TestMultiSearch(t *testing.T) {
+	c := setup(t)
+	defer teardown()
+
+	var sent, method string
+	mux.HandleFunc("/_msearch", func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		sent = string(body)
+		method = r.Method
+		w.Write([]byte(`{"responses":[
+			{"took":2,"timed_out":false,"_shards":{"total":5,"successful":5,"failed":0},
+			 "hits":{"total":1,"hits":[{"_index":"github","_type":"issue","_id":"7","_score":1.0,"_source":{}}]},
+			 "aggregations":{"states":{"buckets":[{"key":"open","doc_count":1}]}}},
+			{"error":"IndexMissingException[[nope] missing]"},
+			{"error":{"type":"search_phase_execution_exception","reason":"all shards failed"}}
+		]}`))
+	})
+
+	searches := []*SearchDsl{
+		Search("github").Type("issue").Type("pr").Size("10").From("20").
+			Query(Query().Term("user", "kimchy")).
+			Aggregates(Aggregate("states").Terms("state")),
+		Search("nope").SearchType("count").Preference("_local").Routing("a", "b").
+			Fields("title", "user").Source(false),
+		Search("").SourceFields("title"),
+	}
+	results, err := c.MultiSearch(context.Background(), searches, nil)
+	assert.T(t, err == nil, fmt.Sprintf("Should not have any errors %v", err))
+	assert.Equal(t, "POST", method)
+
+	lines := strings.Split(strings.TrimSuffix(sent, "\n"), "\n")
+	assert.Equal(t, 6, len(lines))
+	assertJsonMatch(t, []byte(lines[0]), []byte(`{"index":"github","type":"issue,pr"}`))
+	assertJsonMatch(t, []byte(lines[1]), []byte(`{
+		"from": 20, "size": 10,
+		"query": { "term": { "user": "kimchy" } },
+		"aggregations": { "states": { "terms": { "field": "state" } } }
+	}`))
+	assertJsonMatch(t, []byte(lines[2]), []byte(`{"index":"nope","search_type":"count","preference":"_local","routing":"a,b"}`))
+	assertJsonMatch(t, []byte(lines[3]), []byte(`{"fields":["title","user"],"_source":false}`))
+	assertJsonMatch(t, []byte(lines[4]), []byte(`{}`))
+	assertJsonMatch(t, []byte(lines[5]), []byte(`{"_source":["title"]}`))
+
+	assert.Equal(t, 3, len(results))
+	assert.Equal(t, "", results[0].Error)
+	assert.Equal(t, 1, results[0].Hits.Total)
+	assert.Equal(t, "7", results[0].Hits.Hits[0].Id)
+	states, ok := results[0].Aggregations.Terms("states")
+	assert.T(t, ok)
+	assert.Equal(t, "open", states.Buckets[0].KeyString())
+
+	assert.Equal(t, "IndexMissingException[[nope] missing]", results[1].Error)
+	assert.Equal(t, "search_phase_execution_exception: all shards failed", results[2].Error)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	sent = ""
+	_, err = c.MultiSearch(ctx, searches, nil)
+	assert.T(t, err != nil, "Should have failed with a cancelled context")
+	assert.Equal(t, "", sent)
+}
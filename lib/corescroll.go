@@ -0,0 +1,246 @@
+// Copyright 2013 Matthew Baird
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//     http://www.apache.org/licenses/LICENSE-2.0
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package elastigo
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"time"
+)
+
+// DefaultScrollDuration is how long the scroll context is kept alive between
+// pages when the search didn't set Scroll
+var DefaultScrollDuration = "1m"
+
+// ScrollIterator walks every hit of a search a page at a time, keeping track
+// of the scroll id and clearing the scroll context once done.  Create it with
+// SearchDsl.Iterator, always Close it.
+//
+//	iter := Search("github").Scroll("1m").Size("500").Query(...).Iterator(conn)
+//	defer iter.Close()
+//	for {
+//		hit, err := iter.Next(ctx)
+//		if err == io.EOF {
+//			break
+//		} else if err != nil {
+//			return err
+//		}
+//		...
+//	}
+type ScrollIterator struct {
+	// Retries is how many times a page is requested again when the request
+	// provably wasn't served: the connection failed or the node rejected it
+	// with a 429 or 503 status.  Other errors are returned, retrying a scroll
+	// that may have been served would skip its page.
+	Retries int
+	// RetryDelay is the wait before the first retry, doubled on each retry
+	RetryDelay time.Duration
+	// Total is the total hit count of the search, set by the first page
+	Total int
+
+	conn     *Conn
+	search   *SearchDsl
+	duration string
+	scrollId string
+	page     []Hit
+	pos      int
+	started  bool
+	done     bool
+	err      error
+}
+
+// Iterator returns a ScrollIterator over every hit of a copy of the search,
+// with the scan search type hits come back unsorted and the first, empty,
+// page is skipped.
+func (s *SearchDsl) Iterator(conn *Conn) *ScrollIterator {
+	search := s.copy()
+	duration, _ := search.args["scroll"].(string)
+	if duration == "" {
+		duration = DefaultScrollDuration
+		search.Scroll(duration)
+	}
+	return &ScrollIterator{
+		Retries:    3,
+		RetryDelay: 100 * time.Millisecond,
+		conn:       conn,
+		search:     search,
+		duration:   duration,
+	}
+}
+
+// A copy of the search whose args can be changed without changing the
+// caller's search
+func (s *SearchDsl) copy() *SearchDsl {
+	search := *s
+	search.args = make(map[string]interface{}, len(s.args))
+	for key, val := range s.args {
+		search.args[key] = val
+	}
+	return &search
+}
+
+// Next returns the next hit, or io.EOF once every hit has been returned
+func (it *ScrollIterator) Next(ctx context.Context) (Hit, error) {
+	for it.pos >= len(it.page) {
+		if _, err := it.NextPage(ctx); err != nil {
+			return Hit{}, err
+		}
+	}
+	it.pos++
+	return it.page[it.pos-1], nil
+}
+
+// NextPage returns the hits of the next page, or io.EOF once every page has
+// been returned.  Hits not yet returned by Next are skipped.  Cancelling the
+// context aborts the request, or the wait before a retry, and fails the
+// iterator with the context's error.  Once a page fails the error is
+// returned again by every call, the scroll context is left open and still
+// has to be cleared with Close.
+func (it *ScrollIterator) NextPage(ctx context.Context) ([]Hit, error) {
+	if it.err != nil {
+		return nil, it.err
+	}
+	if it.done {
+		return nil, io.EOF
+	}
+	for {
+		var out SearchResult
+		err := it.retry(ctx, func() (err error) {
+			if !it.started {
+				out, err = it.first(ctx)
+			} else {
+				out, err = it.scroll(ctx)
+			}
+			return err
+		})
+		if err != nil {
+			it.page, it.pos = nil, 0
+			it.err = err
+			return nil, err
+		}
+		scan := !it.started && it.search.args["search_type"] == "scan"
+		it.started = true
+		it.Total = out.Hits.Total
+		if out.ScrollId != "" {
+			it.scrollId = out.ScrollId
+		}
+		// the first page of a scan has no hits, only the scroll id
+		if scan && len(out.Hits.Hits) == 0 && out.Hits.Total > 0 {
+			continue
+		}
+		if len(out.Hits.Hits) == 0 {
+			it.page, it.pos = nil, 0
+			it.done = true
+			if err := it.Close(); err != nil {
+				it.err = err
+				return nil, err
+			}
+			return nil, io.EOF
+		}
+		it.page, it.pos = out.Hits.Hits, 0
+		return it.page, nil
+	}
+}
+
+// Close clears the scroll context on the server, it is safe to call more
+// than once and must be called after an error too
+func (it *ScrollIterator) Close() error {
+	it.done = true
+	if it.scrollId == "" {
+		return nil
+	}
+	scrollId := it.scrollId
+	it.scrollId = ""
+	return it.conn.ClearScroll(scrollId)
+}
+
+func (it *ScrollIterator) first(ctx context.Context) (SearchResult, error) {
+	var out SearchResult
+	if err := validateBucketsPaths(it.search.AggregatesVal); err != nil {
+		return out, err
+	}
+	body, err := it.conn.DoCommandContext(ctx, "POST", it.search.url(), it.search.args, it.search)
+	if err != nil {
+		return out, err
+	}
+	err = json.Unmarshal(body, &out)
+	return out, err
+}
+
+func (it *ScrollIterator) scroll(ctx context.Context) (SearchResult, error) {
+	var out SearchResult
+	args := map[string]interface{}{"scroll": it.duration}
+	body, err := it.conn.DoCommandContext(ctx, "POST", "/_search/scroll", args, it.scrollId)
+	if err != nil {
+		return out, err
+	}
+	err = json.Unmarshal(body, &out)
+	return out, err
+}
+
+// Only requests that weren't served are retried, so a retried search can't
+// have opened a scroll context nobody clears and a retried scroll can't have
+// moved past the page it lost
+func (it *ScrollIterator) retry(ctx context.Context, fn func() error) error {
+	delay := it.RetryDelay
+	err := fn()
+	for i := 0; i < it.Retries && isUnservedError(err); i++ {
+		select {
+		case <-time.After(delay):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+		delay *= 2
+		err = fn()
+	}
+	return err
+}
+
+// Failing to connect, or a node or proxy rejecting the request as
+// overloaded, means elasticsearch never ran it.  Anything else, a timeout, a
+// dropped connection or a 500, may have happened after it did.
+func isUnservedError(err error) bool {
+	if err == nil {
+		return false
+	}
+	var esErr ESError
+	if errors.As(err, &esErr) {
+		return esErr.Code == 429 || esErr.Code == 503
+	}
+	var opErr *net.OpError
+	if errors.As(err, &opErr) {
+		return opErr.Op == "dial"
+	}
+	// non json errors from a proxy in front of elasticsearch
+	switch err.Error() {
+	case http.StatusText(429), http.StatusText(503):
+		return true
+	}
+	return false
+}
+
+// ClearScroll frees the scroll contexts of the scroll ids, scroll ids that
+// already expired are ignored, elasticsearch answers those with a 404
+func (c *Conn) ClearScroll(scrollIds ...string) error {
+	if len(scrollIds) == 0 {
+		return nil
+	}
+	_, err := c.DoCommand("DELETE", "/_search/scroll", nil, map[string]interface{}{"scroll_id": scrollIds})
+	if err == RecordNotFound {
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,215 @@
+// Copyright 2013 Matthew Baird
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//     http://www.apache.org/licenses/LICENSE-2.0
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package elastigo
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/bmizerany/assert"
+)
+
+func scrollPage(scrollId string, total int, ids ...string) string {
+	hits := ""
+	for i, id := range ids {
+		if i > 0 {
+			hits += ","
+		}
+		hits += fmt.Sprintf(`{"_index":"github","_type":"issue","_id":%q,"_source":{}}`, id)
+	}
+	return fmt.Sprintf(`{"_scroll_id":%q,"hits":{"total":%d,"hits":[%s]}}`, scrollId, total, hits)
+}
+
+func TestScrollIterator(t *testing.T) {
+	c := setup(t)
+	defer teardown()
+
+	var searchArgs string
+	failures := 0
+	mux.HandleFunc("/github/_search", func(w http.ResponseWriter, r *http.Request) {
+		// the search is rejected once, and is retried
+		if failures == 0 {
+			failures++
+			w.WriteHeader(503)
+			w.Write([]byte(`{"error":"unavailable","status":503}`))
+			return
+		}
+		searchArgs = r.URL.RawQuery
+		w.Write([]byte(scrollPage("s1", 3, "1", "2")))
+	})
+	var cleared string
+	mux.HandleFunc("/_search/scroll", func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		if r.Method == "DELETE" {
+			cleared = string(body)
+			w.Write([]byte(`{"succeeded":true}`))
+			return
+		}
+		assert.Equal(t, "1m", r.URL.Query().Get("scroll"))
+		switch string(body) {
+		case "s1":
+			w.Write([]byte(scrollPage("s2", 3, "3")))
+		case "s2":
+			w.Write([]byte(scrollPage("s3", 3)))
+		default:
+			t.Errorf("unexpected scroll id %q", body)
+		}
+	})
+
+	search := Search("github")
+	iter := search.Iterator(c)
+	iter.RetryDelay = 0
+	// the iterator scrolls a copy of the search
+	_, ok := search.args["scroll"]
+	assert.T(t, !ok)
+	var ids []string
+	for {
+		hit, err := iter.Next(context.Background())
+		if err == io.EOF {
+			break
+		}
+		assert.T(t, err == nil, fmt.Sprintf("Should not have any errors %v", err))
+		if err != nil {
+			break
+		}
+		ids = append(ids, hit.Id)
+	}
+	assert.Equal(t, "scroll=1m", searchArgs)
+	assert.Equal(t, []string{"1", "2", "3"}, ids)
+	assert.Equal(t, 3, iter.Total)
+	assert.Equal(t, 1, failures)
+	assertJsonMatch(t, []byte(cleared), []byte(`{"scroll_id":["s3"]}`))
+
+	_, err := iter.NextPage(context.Background())
+	assert.Equal(t, io.EOF, err)
+	assert.T(t, iter.Close() == nil)
+}
+
+func TestScrollIteratorScan(t *testing.T) {
+	c := setup(t)
+	defer teardown()
+
+	mux.HandleFunc("/github/_search", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "scan", r.URL.Query().Get("search_type"))
+		w.Write([]byte(scrollPage("s1", 2)))
+	})
+	var cleared string
+	mux.HandleFunc("/_search/scroll", func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		switch {
+		case r.Method == "DELETE":
+			cleared = string(body)
+		case string(body) == "s1":
+			w.Write([]byte(scrollPage("s2", 2, "1", "2")))
+		default:
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(400)
+			w.Write([]byte(`{"error":"SearchContextMissingException","status":400}`))
+		}
+	})
+
+	iter := Search("github").SearchType("scan").Scroll("5m").Iterator(c)
+	page, err := iter.NextPage(context.Background())
+	assert.T(t, err == nil, fmt.Sprintf("Should not have any errors %v", err))
+	assert.Equal(t, 2, len(page))
+
+	// a failed page is not retried, and is returned again
+	_, err = iter.NextPage(context.Background())
+	assert.T(t, err != nil)
+	_, err = iter.Next(context.Background())
+	assert.T(t, err != nil && err != io.EOF)
+
+	assert.T(t, iter.Close() == nil)
+	assertJsonMatch(t, []byte(cleared), []byte(`{"scroll_id":["s2"]}`))
+}
+
+func TestScrollIteratorErrors(t *testing.T) {
+	c := setup(t)
+	defer teardown()
+
+	mux.HandleFunc("/github/_search", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(scrollPage("s1", 3, "1", "2")))
+	})
+	scrolls := 0
+	mux.HandleFunc("/_search/scroll", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == "DELETE" {
+			return
+		}
+		scrolls++
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(500)
+		w.Write([]byte(`{"error":"failed","status":500}`))
+	})
+
+	// a scroll that may have been served isn't retried, its page would be lost
+	iter := Search("github").Iterator(c)
+	iter.RetryDelay = 0
+	_, err := iter.NextPage(context.Background())
+	assert.T(t, err == nil, fmt.Sprintf("Should not have any errors %v", err))
+	_, err = iter.NextPage(context.Background())
+	assert.T(t, err != nil)
+	assert.Equal(t, 1, scrolls)
+	assert.T(t, iter.Close() == nil)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	iter = Search("github").Iterator(c)
+	_, err = iter.Next(ctx)
+	assert.T(t, err != nil)
+	assert.T(t, iter.Close() == nil)
+
+	dial := &url.Error{Op: "Post", URL: "http://localhost:9200", Err: &net.OpError{Op: "dial", Net: "tcp"}}
+	read := &url.Error{Op: "Post", URL: "http://localhost:9200", Err: &net.OpError{Op: "read", Net: "tcp"}}
+	assert.T(t, isUnservedError(dial))
+	assert.T(t, !isUnservedError(read))
+	assert.T(t, isUnservedError(ESError{Code: 429}))
+	assert.T(t, isUnservedError(ESError{Code: 503}))
+	assert.T(t, !isUnservedError(ESError{Code: 500}))
+	assert.T(t, !isUnservedError(nil))
+}
+
+func TestScrollIteratorCloseExpired(t *testing.T) {
+	c := setup(t)
+	defer teardown()
+
+	mux.HandleFunc("/github/_search", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(scrollPage("s1", 3, "1", "2")))
+	})
+	deletes := 0
+	mux.HandleFunc("/_search/scroll", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "DELETE", r.Method)
+		// the scroll context expired, with or without a json body
+		deletes++
+		if deletes == 1 {
+			w.WriteHeader(404)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(404)
+		w.Write([]byte(`{"succeeded":true,"num_freed":0}`))
+	})
+
+	for i := 0; i < 2; i++ {
+		iter := Search("github").Iterator(c)
+		_, err := iter.NextPage(context.Background())
+		assert.T(t, err == nil, fmt.Sprintf("Should not have any errors %v", err))
+		err = iter.Close()
+		assert.T(t, err == nil, fmt.Sprintf("Should not have any errors %v", err))
+	}
+	assert.Equal(t, 2, deletes)
+}
@@ -0,0 +1,184 @@
+// Copyright 2013 Matthew Baird
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//     http://www.apache.org/licenses/LICENSE-2.0
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package elastigo
+
+import (
+	"context"
+	"io"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// SlicedScroll exports every hit of a search by scrolling Slices slices of
+// it concurrently, which is much faster than one scroll for large indices.
+// Create it with SearchDsl.SlicedScroll.
+//
+//	export := Search("github").Size("1000").SlicedScroll(conn, 8)
+//	export.Progress = func(done, total int) { log.Printf("%d/%d", done, total) }
+//	err := export.Run(ctx, func(hit Hit) error {
+//		return enc.Encode(hit.Source)
+//	})
+type SlicedScroll struct {
+	// Slices is the number of slices scrolled at the same time
+	Slices int
+	// Buffer is how many hits the slices fetch ahead of the consumer, once it
+	// is full the slices wait, default is 1000, below 0 is 0
+	Buffer int
+	// Progress, if set, is called after each hit is consumed with the hits
+	// consumed so far and the total hit count, which is 0 until the first
+	// page of every slice is in
+	Progress func(done, total int)
+	// Retries and RetryDelay of each slice's ScrollIterator, see there
+	Retries    int
+	RetryDelay time.Duration
+
+	conn   *Conn
+	search *SearchDsl
+}
+
+// SlicedScroll returns an export of every hit of the search over slices
+// concurrent scrolls
+func (s *SearchDsl) SlicedScroll(conn *Conn, slices int) *SlicedScroll {
+	if slices < 1 {
+		slices = 1
+	}
+	return &SlicedScroll{Slices: slices, Buffer: 1000, conn: conn, search: s}
+}
+
+// Run calls fn with every hit, from one goroutine so fn needn't be safe for
+// concurrent use.  On the first error, from a slice or returned by fn, or
+// once the context is cancelled, the slices stop, their scroll contexts are
+// cleared and that error, or the context's, is returned once they are all
+// done.
+func (ss *SlicedScroll) Run(ctx context.Context, fn func(Hit) error) error {
+	buffer := ss.Buffer
+	if buffer < 0 {
+		buffer = 0
+	}
+	hits := make(chan Hit, buffer)
+	stop, cancel := context.WithCancel(ctx)
+	defer cancel()
+	var firstErr error
+	var once sync.Once
+	fail := func(err error) {
+		once.Do(func() {
+			firstErr = err
+			cancel()
+		})
+	}
+
+	var total, started int64
+	var wg sync.WaitGroup
+	for i := 0; i < ss.Slices; i++ {
+		wg.Add(1)
+		go func(search *SearchDsl) {
+			defer wg.Done()
+			if err := ss.scroll(stop, search, hits, &total, &started); err != nil {
+				if stop.Err() != nil {
+					// the request was aborted by the stop, not failed
+					err = stop.Err()
+				}
+				fail(err)
+			}
+		}(ss.slice(i))
+	}
+	go func() {
+		wg.Wait()
+		close(hits)
+	}()
+
+	consumed := 0
+	for hit := range hits {
+		if stop.Err() != nil {
+			// drain what the slices sent before stopping
+			continue
+		}
+		if err := fn(hit); err != nil {
+			fail(err)
+			continue
+		}
+		consumed++
+		if ss.Progress != nil {
+			known := 0
+			if atomic.LoadInt64(&started) == int64(ss.Slices) {
+				known = int(atomic.LoadInt64(&total))
+			}
+			ss.Progress(consumed, known)
+		}
+	}
+	if firstErr == nil {
+		return ctx.Err()
+	}
+	return firstErr
+}
+
+// Stream sends every hit to the channel, closing it once done, see Run.
+// Cancel the context to stop a Stream whose channel is no longer read.
+func (ss *SlicedScroll) Stream(ctx context.Context, out chan<- Hit) error {
+	defer close(out)
+	return ss.Run(ctx, func(hit Hit) error {
+		select {
+		case out <- hit:
+			return nil
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	})
+}
+
+// Scroll one slice into hits until it is exhausted or ctx is done
+func (ss *SlicedScroll) scroll(ctx context.Context, search *SearchDsl, hits chan<- Hit, total, started *int64) error {
+	iter := search.Iterator(ss.conn)
+	if ss.Retries > 0 {
+		iter.Retries = ss.Retries
+	}
+	if ss.RetryDelay > 0 {
+		iter.RetryDelay = ss.RetryDelay
+	}
+	defer iter.Close()
+
+	first := true
+	for {
+		page, err := iter.NextPage(ctx)
+		if err != nil && err != io.EOF {
+			return err
+		}
+		if first {
+			first = false
+			atomic.AddInt64(total, int64(iter.Total))
+			atomic.AddInt64(started, 1)
+		}
+		if err == io.EOF {
+			return nil
+		}
+		for _, hit := range page {
+			select {
+			case hits <- hit:
+			case <-ctx.Done():
+				return nil
+			}
+		}
+	}
+}
+
+// A copy of the search limited to slice id
+func (ss *SlicedScroll) slice(id int) *SearchDsl {
+	search := ss.search.copy()
+	if ss.Slices > 1 {
+		search.SliceVal = &SliceDsl{Id: id, Max: ss.Slices}
+		if ss.search.SliceVal != nil {
+			search.SliceVal.Field = ss.search.SliceVal.Field
+		}
+	}
+	return search
+}
@@ -0,0 +1,148 @@
+// Copyright 2013 Matthew Baird
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//     http://www.apache.org/licenses/LICENSE-2.0
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package elastigo
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/bmizerany/assert"
+)
+
+// Serve slices of 2 pages of 2 hits each, ids are slice-n
+func sliceServer(t *testing.T, slices int) (*sync.Mutex, map[string]bool) {
+	var mu sync.Mutex
+	cleared := make(map[string]bool)
+	mux.HandleFunc("/github/_search", func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Slice *SliceDsl `json:"slice"`
+		}
+		json.NewDecoder(r.Body).Decode(&body)
+		if body.Slice == nil || body.Slice.Max != slices {
+			t.Errorf("expected a slice of %d but got %+v", slices, body.Slice)
+			return
+		}
+		id := body.Slice.Id
+		w.Write([]byte(scrollPage(fmt.Sprintf("%d-1", id), 4, fmt.Sprintf("%d-0", id), fmt.Sprintf("%d-1", id))))
+	})
+	mux.HandleFunc("/_search/scroll", func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		if r.Method == "DELETE" {
+			var req struct {
+				ScrollId []string `json:"scroll_id"`
+			}
+			json.Unmarshal(body, &req)
+			mu.Lock()
+			for _, id := range req.ScrollId {
+				cleared[id] = true
+			}
+			mu.Unlock()
+			return
+		}
+		parts := strings.Split(string(body), "-")
+		if parts[1] == "1" {
+			w.Write([]byte(scrollPage(parts[0]+"-2", 4, parts[0]+"-2", parts[0]+"-3")))
+		} else {
+			w.Write([]byte(scrollPage(parts[0]+"-3", 4)))
+		}
+	})
+	return &mu, cleared
+}
+
+func TestSlicedScroll(t *testing.T) {
+	c := setup(t)
+	defer teardown()
+	mu, cleared := sliceServer(t, 3)
+
+	export := Search("github").Size("2").SlicedScroll(c, 3)
+	export.Buffer = 1
+	lastDone, lastTotal := 0, 0
+	export.Progress = func(done, total int) {
+		// the total is only known once every slice has started
+		if total != 0 && total != 12 {
+			t.Errorf("expected a total of 0 or 12 but got %d", total)
+		}
+		lastDone, lastTotal = done, total
+	}
+	var ids []string
+	err := export.Run(context.Background(), func(hit Hit) error {
+		ids = append(ids, hit.Id)
+		return nil
+	})
+	assert.T(t, err == nil, fmt.Sprintf("Should not have any errors %v", err))
+	sort.Strings(ids)
+	assert.Equal(t, []string{"0-0", "0-1", "0-2", "0-3", "1-0", "1-1", "1-2", "1-3", "2-0", "2-1", "2-2", "2-3"}, ids)
+	assert.Equal(t, 12, lastDone)
+	assert.Equal(t, 12, lastTotal)
+	mu.Lock()
+	assert.Equal(t, 3, len(cleared))
+	mu.Unlock()
+}
+
+func TestSlicedScrollError(t *testing.T) {
+	c := setup(t)
+	defer teardown()
+	mu, cleared := sliceServer(t, 2)
+
+	stop := errors.New("stop")
+	consumed := 0
+	err := Search("github").SlicedScroll(c, 2).Run(context.Background(), func(hit Hit) error {
+		if consumed++; consumed == 3 {
+			return stop
+		}
+		return nil
+	})
+	assert.Equal(t, stop, err)
+	assert.Equal(t, 3, consumed)
+	// every slice that got a scroll id cleared it
+	mu.Lock()
+	assert.Equal(t, 2, len(cleared))
+	mu.Unlock()
+
+	hits := make(chan Hit)
+	var streamed int
+	go func() {
+		for range hits {
+			streamed++
+		}
+	}()
+	export := Search("github").SlicedScroll(c, 2)
+	export.Buffer = -1
+	err = export.Stream(context.Background(), hits)
+	assert.T(t, err == nil, fmt.Sprintf("Should not have any errors %v", err))
+}
+
+func TestSlicedScrollCancel(t *testing.T) {
+	c := setup(t)
+	defer teardown()
+	mu, cleared := sliceServer(t, 2)
+
+	// nobody reads the channel, cancelling stops the stream
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	export := Search("github").SlicedScroll(c, 2)
+	export.Buffer = 0
+	err := export.Stream(ctx, make(chan Hit))
+	assert.Equal(t, context.DeadlineExceeded, err)
+	mu.Lock()
+	assert.Equal(t, 2, len(cleared))
+	mu.Unlock()
+}
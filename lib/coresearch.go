@@ -56,6 +56,8 @@ func (c *Conn) Search(index string, _type string, args map[string]interface{}, q
 	return retval, err
 }
 
+// Suggest runs suggesters without a search, query is the body, such as a
+// map[string]*SuggestDsl of Suggest() builders by name
 func (c *Conn) Suggest(index string, args map[string]interface{}, query interface{}) (SuggestResults, error) {
 	uriVal := fmt.Sprintf("/%s/_suggest", index)
 	body, err := c.DoCommand("POST", uriVal, args, query)
@@ -134,6 +136,8 @@ func (c *Conn) SearchUri(index, _type string, args map[string]interface{}) (Sear
 	return retval, err
 }
 
+// Scroll fetches the next page of a scroll, see SearchDsl.Iterator to walk
+// every page without handling the scroll id
 func (c *Conn) Scroll(args map[string]interface{}, scroll_id string) (SearchResult, error) {
 	var url string
 	var retval SearchResult
@@ -162,6 +166,11 @@ type SuggestionOption struct {
 	Payload json.RawMessage `json:"payload"`
 	Score   Float32Nullable `json:"score,omitempty"`
 	Text    string          `json:"text"`
+	// term suggestions only
+	Freq int `json:"freq,omitempty"`
+	// phrase suggestions with Highlight and Collate only
+	Highlighted  string `json:"highlighted,omitempty"`
+	CollateMatch *bool  `json:"collate_match,omitempty"`
 }
 
 type Suggestion struct {
@@ -181,8 +190,10 @@ type SearchResult struct {
 	Hits         Hits            `json:"hits"`
 	Facets       json.RawMessage `json:"facets,omitempty"` // structure varies on query
 	ScrollId     string          `json:"_scroll_id,omitempty"`
-	Aggregations json.RawMessage `json:"aggregations,omitempty"` // structure varies on query
+	Aggregations Aggregations    `json:"aggregations,omitempty"` // structure varies on query, see the Aggregations accessors
 	Suggestions  Suggestions     `json:"suggest,omitempty"`
+	// set by MultiSearch when this search failed
+	Error string `json:"-"`
 }
 
 func (s *SearchResult) String() string {
@@ -199,8 +210,59 @@ func (h *Hits) Len() int {
 	return len(h.Hits)
 }
 
+// Highlight holds the highlighted fragments of a Hit, by field
 type Highlight map[string][]string
 
+// HighlightEllipsis separates the fragments of a Snippet
+var HighlightEllipsis = " ... "
+
+// Snippet joins the fragments of the field, up to number_of_fragments of
+// them, with HighlightEllipsis.  Empty if the field has no highlights.
+func (h Highlight) Snippet(field string) string {
+	return strings.Join(h[field], HighlightEllipsis)
+}
+
+// Apply replaces each highlighted field of a decoded _source with its
+// Snippet.  Dotted fields follow nested objects, and highlights of multi
+// fields ("title.english") replace the field they are part of.  Fields that
+// are not in the source, or are inside arrays, are left alone.
+func (h Highlight) Apply(source map[string]interface{}) {
+	for field, fragments := range h {
+		if len(fragments) == 0 {
+			continue
+		}
+		obj := source
+		for _, key := range strings.Split(field, ".") {
+			val, ok := obj[key]
+			if !ok {
+				break
+			}
+			if child, isObj := val.(map[string]interface{}); isObj {
+				obj = child
+				continue
+			}
+			if _, isArr := val.([]interface{}); !isArr {
+				obj[key] = h.Snippet(field)
+			}
+			break
+		}
+	}
+}
+
+// HighlightedSource decodes the _source of the hit with the highlighted
+// fields replaced by their fragments, see Highlight.Apply
+func (h *Hit) HighlightedSource() (map[string]interface{}, error) {
+	source := make(map[string]interface{})
+	if h.Source == nil {
+		return source, nil
+	}
+	if err := json.Unmarshal(*h.Source, &source); err != nil {
+		return nil, err
+	}
+	h.Highlight.Apply(source)
+	return source, nil
+}
+
 // TTL is a wrapper around time.Time that converts a number of milliseconds in the future to a time.Time.
 type TTL struct{ time.Time }
 
@@ -222,8 +284,12 @@ type Hit struct {
 	TTL         *TTL             `json:"_ttl,omitempty"`
 	Fields      *json.RawMessage `json:"fields"` // when a field arg is passed to ES, instead of _source it returns fields
 	Explanation *Explanation     `json:"_explanation,omitempty"`
-	Highlight   *Highlight       `json:"highlight,omitempty"`
+	Highlight   Highlight        `json:"highlight,omitempty"`
 	Sort        []interface{}    `json:"sort,omitempty"`
+	// keyed by the inner_hits name, the nested path or child/parent type
+	InnerHits map[string]InnerHitsResult `json:"inner_hits,omitempty"`
+	// set on inner hits of nested objects
+	Nested *NestedIdentity `json:"_nested,omitempty"`
 }
 
 func (e *Explanation) String(indent string) string {
@@ -81,3 +81,37 @@ func TestCoreSearch(t *testing.T) {
 		So(opts[0].Options[0].Text, ShouldEqual, "foobar")
 	})
 }
+
+func TestHitInnerHits(t *testing.T) {
+	Convey("Parse the inner hits of a hit", t, func() {
+		body := []byte(`{
+			"_index": "blog", "_type": "post", "_id": "1", "_score": 1.2, "_source": {},
+			"inner_hits": {
+				"comments": {
+					"hits": {
+						"total": 2,
+						"max_score": 0.9,
+						"hits": [
+							{
+								"_index": "blog", "_type": "post", "_id": "1", "_score": 0.9,
+								"_nested": { "field": "comments", "offset": 1 },
+								"_source": { "text": "great" }
+							}
+						]
+					}
+				}
+			}
+		}`)
+		var hit Hit
+		err := json.Unmarshal(body, &hit)
+		So(err, ShouldBeNil)
+		So(len(hit.InnerHits), ShouldEqual, 1)
+
+		comments := hit.InnerHits["comments"].Hits
+		So(comments.Total, ShouldEqual, 2)
+		So(comments.Len(), ShouldEqual, 1)
+		So(comments.Hits[0].Nested, ShouldNotBeNil)
+		So(comments.Hits[0].Nested.Field, ShouldEqual, "comments")
+		So(comments.Hits[0].Nested.Offset, ShouldEqual, 1)
+	})
+}
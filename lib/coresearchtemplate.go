@@ -0,0 +1,135 @@
+// Copyright 2013 Matthew Baird
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//     http://www.apache.org/licenses/LICENSE-2.0
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package elastigo
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+)
+
+// PutSearchTemplate stores a mustache search template under the id, the
+// template is a search body with {{param}} placeholders, as a string or any
+// value marshalable to json
+//
+//	c.PutSearchTemplate(ctx, "by_user", map[string]interface{}{
+//		"query": map[string]interface{}{"term": map[string]string{"user": "{{user}}"}},
+//	})
+//
+// http://www.elastic.co/guide/en/elasticsearch/reference/1.x/search-template.html
+func (c *Conn) PutSearchTemplate(ctx context.Context, id string, template interface{}) (BaseResponse, error) {
+	var retval BaseResponse
+	url := fmt.Sprintf("/_search/template/%s", id)
+	body, err := c.DoCommandContext(ctx, "PUT", url, nil, map[string]interface{}{"template": template})
+	if err != nil {
+		return retval, err
+	}
+	jsonErr := json.Unmarshal(body, &retval)
+	return retval, jsonErr
+}
+
+type SearchTemplateResponse struct {
+	Id      string `json:"_id"`
+	Version int    `json:"_version,omitempty"`
+	Found   bool   `json:"found"`
+	// the stored template, json even if it was stored as a string of json
+	Template json.RawMessage `json:"template"`
+}
+
+// GetSearchTemplate returns a template stored with PutSearchTemplate
+func (c *Conn) GetSearchTemplate(ctx context.Context, id string) (SearchTemplateResponse, error) {
+	var retval SearchTemplateResponse
+	url := fmt.Sprintf("/_search/template/%s", id)
+	body, err := c.DoCommandContext(ctx, "GET", url, nil, nil)
+	if err != nil {
+		return retval, err
+	}
+	if jsonErr := json.Unmarshal(body, &retval); jsonErr != nil {
+		return retval, jsonErr
+	}
+	// templates come back as the string they are stored as
+	var str string
+	if json.Unmarshal(retval.Template, &str) == nil {
+		var tmpl json.RawMessage
+		if json.Unmarshal([]byte(str), &tmpl) == nil {
+			retval.Template = tmpl
+		}
+	}
+	return retval, nil
+}
+
+func (c *Conn) DeleteSearchTemplate(ctx context.Context, id string) (BaseResponse, error) {
+	var retval BaseResponse
+	url := fmt.Sprintf("/_search/template/%s", id)
+	body, err := c.DoCommandContext(ctx, "DELETE", url, nil, nil)
+	if err != nil {
+		return retval, err
+	}
+	jsonErr := json.Unmarshal(body, &retval)
+	return retval, jsonErr
+}
+
+// SearchTemplate searches the index, or all indices if empty, with the stored
+// template filled in with the params
+func (c *Conn) SearchTemplate(ctx context.Context, index, id string, params map[string]interface{}, args map[string]interface{}) (SearchResult, error) {
+	return c.searchTemplate(ctx, index, map[string]string{"id": id}, params, args)
+}
+
+// SearchInlineTemplate is SearchTemplate with the mustache template in the
+// request, as a string or any value marshalable to json, rather than stored
+func (c *Conn) SearchInlineTemplate(ctx context.Context, index string, template interface{}, params map[string]interface{}, args map[string]interface{}) (SearchResult, error) {
+	return c.searchTemplate(ctx, index, template, params, args)
+}
+
+func (c *Conn) searchTemplate(ctx context.Context, index string, template interface{}, params map[string]interface{}, args map[string]interface{}) (SearchResult, error) {
+	var retval SearchResult
+	url := "/_search/template"
+	if len(index) > 0 {
+		url = fmt.Sprintf("/%s/_search/template", index)
+	}
+	query := map[string]interface{}{
+		"template": template,
+		"params":   params,
+	}
+	body, err := c.DoCommandContext(ctx, "POST", url, args, query)
+	if err != nil {
+		return retval, err
+	}
+	jsonErr := json.Unmarshal(body, &retval)
+	retval.RawJSON = body
+	return retval, jsonErr
+}
+
+// RenderSearchTemplate returns the search body the stored template produces
+// with the params, without searching
+func (c *Conn) RenderSearchTemplate(ctx context.Context, id string, params map[string]interface{}) (json.RawMessage, error) {
+	url := fmt.Sprintf("/_render/template/%s", id)
+	return c.renderTemplate(ctx, url, map[string]interface{}{"params": params})
+}
+
+// RenderInlineTemplate returns the search body the template produces with the
+// params, to debug a template before storing it
+func (c *Conn) RenderInlineTemplate(ctx context.Context, template interface{}, params map[string]interface{}) (json.RawMessage, error) {
+	return c.renderTemplate(ctx, "/_render/template", map[string]interface{}{"template": template, "params": params})
+}
+
+func (c *Conn) renderTemplate(ctx context.Context, url string, query map[string]interface{}) (json.RawMessage, error) {
+	body, err := c.DoCommandContext(ctx, "POST", url, nil, query)
+	if err != nil {
+		return nil, err
+	}
+	var rendered struct {
+		TemplateOutput json.RawMessage `json:"template_output"`
+	}
+	jsonErr := json.Unmarshal(body, &rendered)
+	return rendered.TemplateOutput, jsonErr
+}
@@ -0,0 +1,113 @@
+// Copyright 2013 Matthew Baird
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//     http://www.apache.org/licenses/LICENSE-2.0
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package elastigo
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"testing"
+
+	"github.com/bmizerany/assert"
+)
+
+func TestSearchTemplates(t *testing.T) {
+	c := setup(t)
+	defer teardown()
+
+	requests := make(map[string]string)
+	mux.HandleFunc("/_search/template/by_user", func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		requests[r.Method] = string(body)
+		switch r.Method {
+		case "PUT":
+			w.Write([]byte(`{"_index":".scripts","_type":"mustache","_id":"by_user","_version":1,"created":true}`))
+		case "GET":
+			w.Write([]byte(`{"_index":".scripts","_type":"mustache","_id":"by_user","_version":1,"found":true,
+				"lang":"mustache","template":"{\"query\":{\"term\":{\"user\":\"{{user}}\"}}}"}`))
+		case "DELETE":
+			w.Write([]byte(`{"found":true,"_index":".scripts","_type":"mustache","_id":"by_user","_version":2}`))
+		}
+	})
+	mux.HandleFunc("/github/_search/template", func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		requests["search"] = string(body)
+		w.Write([]byte(`{"took":1,"hits":{"total":1,"hits":[{"_index":"github","_type":"issue","_id":"3","_source":{}}]}}`))
+	})
+	mux.HandleFunc("/_render/template/by_user", func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		requests["render"] = string(body)
+		w.Write([]byte(`{"template_output":{"query":{"term":{"user":"kimchy"}}}}`))
+	})
+
+	template := map[string]interface{}{
+		"query": map[string]interface{}{"term": map[string]string{"user": "{{user}}"}},
+	}
+	put, err := c.PutSearchTemplate(context.Background(), "by_user", template)
+	assert.T(t, err == nil, fmt.Sprintf("Should not have any errors %v", err))
+	assert.T(t, put.Created)
+	assertJsonMatch(t, []byte(requests["PUT"]), []byte(`{"template":{"query":{"term":{"user":"{{user}}"}}}}`))
+
+	got, err := c.GetSearchTemplate(context.Background(), "by_user")
+	assert.T(t, err == nil, fmt.Sprintf("Should not have any errors %v", err))
+	assert.T(t, got.Found)
+	assertJsonMatch(t, got.Template, []byte(`{"query":{"term":{"user":"{{user}}"}}}`))
+
+	params := map[string]interface{}{"user": "kimchy"}
+	out, err := c.SearchTemplate(context.Background(), "github", "by_user", params, nil)
+	assert.T(t, err == nil, fmt.Sprintf("Should not have any errors %v", err))
+	assert.Equal(t, 1, out.Hits.Total)
+	assert.Equal(t, "3", out.Hits.Hits[0].Id)
+	assertJsonMatch(t, []byte(requests["search"]), []byte(`{"template":{"id":"by_user"},"params":{"user":"kimchy"}}`))
+
+	rendered, err := c.RenderSearchTemplate(context.Background(), "by_user", params)
+	assert.T(t, err == nil, fmt.Sprintf("Should not have any errors %v", err))
+	assertJsonMatch(t, rendered, []byte(`{"query":{"term":{"user":"kimchy"}}}`))
+	assertJsonMatch(t, []byte(requests["render"]), []byte(`{"params":{"user":"kimchy"}}`))
+
+	deleted, err := c.DeleteSearchTemplate(context.Background(), "by_user")
+	assert.T(t, err == nil, fmt.Sprintf("Should not have any errors %v", err))
+	assert.T(t, deleted.Found)
+}
+
+func TestInlineSearchTemplates(t *testing.T) {
+	c := setup(t)
+	defer teardown()
+
+	requests := make(map[string]string)
+	mux.HandleFunc("/_search/template", func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		requests["search"] = string(body)
+		w.Write([]byte(`{"took":1,"hits":{"total":1,"hits":[{"_index":"github","_type":"issue","_id":"3","_source":{}}]}}`))
+	})
+	mux.HandleFunc("/_render/template", func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		requests["render"] = string(body)
+		w.Write([]byte(`{"template_output":{"query":{"term":{"user":"kimchy"}}}}`))
+	})
+
+	template := map[string]interface{}{
+		"query": map[string]interface{}{"term": map[string]string{"user": "{{user}}"}},
+	}
+	params := map[string]interface{}{"user": "kimchy"}
+	out, err := c.SearchInlineTemplate(context.Background(), "", template, params, nil)
+	assert.T(t, err == nil, fmt.Sprintf("Should not have any errors %v", err))
+	assert.Equal(t, "3", out.Hits.Hits[0].Id)
+	assertJsonMatch(t, []byte(requests["search"]), []byte(`{"template":{"query":{"term":{"user":"{{user}}"}}},"params":{"user":"kimchy"}}`))
+
+	// templates that aren't valid json until rendered are strings
+	rendered, err := c.RenderInlineTemplate(context.Background(), `{"query":{"term":{"user":"{{user}}"}},"size":{{size}}}`, params)
+	assert.T(t, err == nil, fmt.Sprintf("Should not have any errors %v", err))
+	assertJsonMatch(t, rendered, []byte(`{"query":{"term":{"user":"kimchy"}}}`))
+	assertJsonMatch(t, []byte(requests["render"]), []byte(`{"template":"{\"query\":{\"term\":{\"user\":\"{{user}}\"}},\"size\":{{size}}}","params":{"user":"kimchy"}}`))
+}
@@ -1,8 +1,30 @@
 package elastigo
 
 import (
+	"encoding/json"
 	"errors"
+	"fmt"
 )
 
 // 404 Response.
 var RecordNotFound = errors.New("record not found")
+
+// The error of a bulk item or multi search response, a string on older
+// versions and an object on newer ones, as "type: reason"
+func esErrorString(raw json.RawMessage) string {
+	if len(raw) == 0 || string(raw) == "null" {
+		return ""
+	}
+	var msg string
+	if json.Unmarshal(raw, &msg) == nil {
+		return msg
+	}
+	var cause struct {
+		Type   string `json:"type"`
+		Reason string `json:"reason"`
+	}
+	if json.Unmarshal(raw, &cause) == nil && cause.Reason != "" {
+		return fmt.Sprintf("%s: %s", cause.Type, cause.Reason)
+	}
+	return string(raw)
+}
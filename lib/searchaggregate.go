@@ -15,8 +15,11 @@ type AggregateDsl struct {
 }
 
 type FieldAggregate struct {
-	Field string `json:"field"`
-	Size  *int   `json:"size,omitempty"`
+	Field  string                 `json:"field,omitempty"`
+	Size   *int                   `json:"size,omitempty"`
+	Script string                 `json:"script,omitempty"`
+	Params map[string]interface{} `json:"params,omitempty"`
+	Lang   string                 `json:"lang,omitempty"`
 }
 
 /**
@@ -90,6 +93,116 @@ func (d *AggregateDsl) Percentiles(field string) *AggregateDsl {
 	return d
 }
 
+/**
+ * Script sets a script as the value source of a Min, Max, Sum, Avg, Stats,
+ * ExtendedStats, ValueCount, Percentiles, Missing or Terms aggregate.  With
+ * an empty field the script computes the values, otherwise it transforms the
+ * field values available as _value.
+ *
+ * Aggregate("avg_grade").Avg("").Script("doc['grade'].value * correction",
+ *   map[string]interface{}{"correction": 1.2})
+ */
+func (d *AggregateDsl) Script(script string, params map[string]interface{}) *AggregateDsl {
+	switch agg := d.Type.(type) {
+	case FieldAggregate:
+		agg.Script = script
+		agg.Params = params
+		d.Type = agg
+	case TermsAggregate:
+		agg.Script = script
+		agg.Params = params
+		d.Type = agg
+	}
+	return d
+}
+
+type PercentileRanksAggregate struct {
+	Field  string    `json:"field"`
+	Values []float64 `json:"values"`
+}
+
+// PercentileRanks returns the percentile rank of each of the values
+func (d *AggregateDsl) PercentileRanks(field string, values ...float64) *AggregateDsl {
+	d.Type = PercentileRanksAggregate{Field: field, Values: values}
+	d.TypeName = "percentile_ranks"
+	return d
+}
+
+type TopHitsAggregate struct {
+	Size   int                 `json:"size,omitempty"`
+	Sort   []*SortDsl          `json:"sort,omitempty"`
+	Source map[string][]string `json:"_source,omitempty"`
+}
+
+/**
+ * TopHits returns the top size docs of each bucket, sorted by score unless
+ * sort is given, with only the sourceFields of the _source if any
+ *
+ * Aggregate("top_tags").Terms("tags").Aggregates(
+ *   Aggregate("latest").TopHits(1, []*SortDsl{Sort("date").Desc()}, []string{"title"}),
+ * )
+ */
+func (d *AggregateDsl) TopHits(size int, sort []*SortDsl, sourceFields []string) *AggregateDsl {
+	agg := TopHitsAggregate{Size: size, Sort: sort}
+	if len(sourceFields) > 0 {
+		agg.Source = map[string][]string{"include": sourceFields}
+	}
+	d.Type = agg
+	d.TypeName = "top_hits"
+	return d
+}
+
+type GeoBoundsAggregate struct {
+	Field         string `json:"field"`
+	WrapLongitude bool   `json:"wrap_longitude"`
+}
+
+// GeoBounds returns the bounding box of the geo_point field, wrapLongitude
+// allows it to overlap the international date line
+func (d *AggregateDsl) GeoBounds(field string, wrapLongitude bool) *AggregateDsl {
+	d.Type = GeoBoundsAggregate{Field: field, WrapLongitude: wrapLongitude}
+	d.TypeName = "geo_bounds"
+	return d
+}
+
+// GeoCentroid returns the centroid of the geo_point field
+func (d *AggregateDsl) GeoCentroid(field string) *AggregateDsl {
+	d.Type = FieldAggregate{Field: field}
+	d.TypeName = "geo_centroid"
+	return d
+}
+
+type ScriptedMetricAggregate struct {
+	InitScript    string                 `json:"init_script,omitempty"`
+	MapScript     string                 `json:"map_script"`
+	CombineScript string                 `json:"combine_script,omitempty"`
+	ReduceScript  string                 `json:"reduce_script,omitempty"`
+	Params        map[string]interface{} `json:"params,omitempty"`
+}
+
+/**
+ * ScriptedMetric computes a metric with scripts, only mapScript is required
+ *
+ * Aggregate("profit").ScriptedMetric(
+ *   "_agg['transactions'] = []",
+ *   "_agg.transactions.add(doc['amount'].value)",
+ *   "profit = 0; for (t in _agg.transactions) { profit += t }; return profit",
+ *   "profit = 0; for (a in _aggs) { profit += a }; return profit",
+ *   nil,
+ * )
+ */
+func (d *AggregateDsl) ScriptedMetric(initScript, mapScript, combineScript, reduceScript string, params map[string]interface{}) *AggregateDsl {
+	d.Type = ScriptedMetricAggregate{
+		InitScript:    initScript,
+		MapScript:     mapScript,
+		CombineScript: combineScript,
+		ReduceScript:  reduceScript,
+		Params:        params,
+	}
+	d.TypeName = "scripted_metric"
+	return d
+}
+
 type Cardinality struct {
 	Field              string  `json:"field"`
 	PrecisionThreshold float64 `json:"precision_threshold,omitempty"`
@@ -145,20 +258,39 @@ func (d *AggregateDsl) Missing(field string) *AggregateDsl {
 	return d
 }
 
+// TermsAggregate is a terms or significant_terms aggregate, see Order,
+// MinDocCount, ShardSize, Include, Exclude, CollectMode and MissingValue
+type TermsAggregate struct {
+	FieldAggregate
+	BucketOptions
+	ShardSize   int         `json:"shard_size,omitempty"`
+	Include     interface{} `json:"include,omitempty"`
+	Exclude     interface{} `json:"exclude,omitempty"`
+	CollectMode string      `json:"collect_mode,omitempty"`
+}
+
+// BucketOptions are the options shared by terms and histogram aggregates
+type BucketOptions struct {
+	// {"_count": "desc"}, or an array of them to break ties
+	Order       interface{} `json:"order,omitempty"`
+	MinDocCount *int        `json:"min_doc_count,omitempty"`
+	Missing     interface{} `json:"missing,omitempty"`
+}
+
 func (d *AggregateDsl) Terms(field string) *AggregateDsl {
-	d.Type = FieldAggregate{Field: field}
+	d.Type = TermsAggregate{FieldAggregate: FieldAggregate{Field: field}}
 	d.TypeName = "terms"
 	return d
 }
 
 func (d *AggregateDsl) TermsWithSize(field string, size int) *AggregateDsl {
-	d.Type = FieldAggregate{Field: field, Size: &size}
+	d.Type = TermsAggregate{FieldAggregate: FieldAggregate{Field: field, Size: &size}}
 	d.TypeName = "terms"
 	return d
 }
 
 func (d *AggregateDsl) SignificantTerms(field string) *AggregateDsl {
-	d.Type = FieldAggregate{Field: field}
+	d.Type = TermsAggregate{FieldAggregate: FieldAggregate{Field: field}}
 	d.TypeName = "significant_terms"
 	return d
 }
@@ -166,6 +298,23 @@ func (d *AggregateDsl) SignificantTerms(field string) *AggregateDsl {
 type Histogram struct {
 	Field    string  `json:"field"`
 	Interval float64 `json:"interval"`
+	BucketOptions
+	HistogramOptions
+}
+
+// HistogramOptions are the options of histogram and date_histogram
+// aggregates, see ExtendedBounds, Offset, TimeZone and Format
+type HistogramOptions struct {
+	ExtendedBounds *ExtendedBounds `json:"extended_bounds,omitempty"`
+	// a number for histograms, "+6h" for date histograms
+	Offset   interface{} `json:"offset,omitempty"`
+	TimeZone string      `json:"time_zone,omitempty"`
+	Format   string      `json:"format,omitempty"`
+}
+
+type ExtendedBounds struct {
+	Min interface{} `json:"min"`
+	Max interface{} `json:"max"`
 }
 
 func (d *AggregateDsl) Histogram(field string, interval int) *AggregateDsl {
@@ -180,6 +329,8 @@ func (d *AggregateDsl) Histogram(field string, interval int) *AggregateDsl {
 type DateHistogram struct {
 	Field    string `json:"field"`
 	Interval string `json:"interval"`
+	BucketOptions
+	HistogramOptions
 }
 
 func (d *AggregateDsl) DateHistogram(field, interval string) *AggregateDsl {
@@ -191,7 +342,279 @@ func (d *AggregateDsl) DateHistogram(field, interval string) *AggregateDsl {
 	return d
 }
 
+// Update the BucketOptions of a terms or histogram aggregate
+func (d *AggregateDsl) bucketOptions(set func(*BucketOptions)) *AggregateDsl {
+	switch agg := d.Type.(type) {
+	case TermsAggregate:
+		set(&agg.BucketOptions)
+		d.Type = agg
+	case Histogram:
+		set(&agg.BucketOptions)
+		d.Type = agg
+	case DateHistogram:
+		set(&agg.BucketOptions)
+		d.Type = agg
+	}
+	return d
+}
+
+// Update the HistogramOptions of a histogram or date_histogram aggregate
+func (d *AggregateDsl) histogramOptions(set func(*HistogramOptions)) *AggregateDsl {
+	switch agg := d.Type.(type) {
+	case Histogram:
+		set(&agg.HistogramOptions)
+		d.Type = agg
+	case DateHistogram:
+		set(&agg.HistogramOptions)
+		d.Type = agg
+	}
+	return d
+}
+
+/**
+ * Order sorts the buckets of a terms or histogram aggregate by _count,
+ * _term, _key or a sub-aggregate metric ("avg_price" or "stats.max"),
+ * direction is asc or desc.  Call it again to break ties.
+ *
+ * Aggregate("genders").Terms("gender").Order("avg_height", "desc").Order("_term", "asc")
+ */
+func (d *AggregateDsl) Order(key, direction string) *AggregateDsl {
+	return d.bucketOptions(func(o *BucketOptions) {
+		order := map[string]string{key: direction}
+		switch existing := o.Order.(type) {
+		case map[string]string:
+			o.Order = []map[string]string{existing, order}
+		case []map[string]string:
+			o.Order = append(existing, order)
+		default:
+			o.Order = order
+		}
+	})
+}
+
+// MinDocCount sets the min doc count of the returned buckets, 0 returns
+// empty buckets, which with ExtendedBounds fills gaps in histograms
+func (d *AggregateDsl) MinDocCount(min int) *AggregateDsl {
+	return d.bucketOptions(func(o *BucketOptions) {
+		o.MinDocCount = &min
+	})
+}
+
+// MissingValue sets the value used for docs without the field, they are
+// ignored otherwise
+func (d *AggregateDsl) MissingValue(missing interface{}) *AggregateDsl {
+	return d.bucketOptions(func(o *BucketOptions) {
+		o.Missing = missing
+	})
+}
+
+// ShardSize sets how many terms each shard returns, more than the size
+// improves accuracy
+func (d *AggregateDsl) ShardSize(size int) *AggregateDsl {
+	if agg, ok := d.Type.(TermsAggregate); ok {
+		agg.ShardSize = size
+		d.Type = agg
+	}
+	return d
+}
+
+// Include limits the terms to a regex string, or a []string of exact values
+func (d *AggregateDsl) Include(include interface{}) *AggregateDsl {
+	if agg, ok := d.Type.(TermsAggregate); ok {
+		agg.Include = include
+		d.Type = agg
+	}
+	return d
+}
+
+// Exclude drops the terms matching a regex string, or in a []string of exact
+// values
+func (d *AggregateDsl) Exclude(exclude interface{}) *AggregateDsl {
+	if agg, ok := d.Type.(TermsAggregate); ok {
+		agg.Exclude = exclude
+		d.Type = agg
+	}
+	return d
+}
+
+// CollectMode sets how sub-aggregates of terms are computed, depth_first or
+// breadth_first
+func (d *AggregateDsl) CollectMode(mode string) *AggregateDsl {
+	if agg, ok := d.Type.(TermsAggregate); ok {
+		agg.CollectMode = mode
+		d.Type = agg
+	}
+	return d
+}
+
+// ExtendedBounds makes a histogram return buckets from min to max even if
+// they are empty, use with MinDocCount(0)
+func (d *AggregateDsl) ExtendedBounds(min, max interface{}) *AggregateDsl {
+	return d.histogramOptions(func(o *HistogramOptions) {
+		o.ExtendedBounds = &ExtendedBounds{Min: min, Max: max}
+	})
+}
+
+// Offset shifts the bucket boundaries of a histogram, a number, or "+6h"
+// for date histograms
+func (d *AggregateDsl) Offset(offset interface{}) *AggregateDsl {
+	return d.histogramOptions(func(o *HistogramOptions) {
+		o.Offset = offset
+	})
+}
+
+// TimeZone sets the time zone of the buckets of a date histogram, "-01:00"
+// or "America/Los_Angeles"
+func (d *AggregateDsl) TimeZone(timeZone string) *AggregateDsl {
+	return d.histogramOptions(func(o *HistogramOptions) {
+		o.TimeZone = timeZone
+	})
+}
+
+// Format sets the format of the key_as_string of histogram buckets, and of
+// date extended bounds
+func (d *AggregateDsl) Format(format string) *AggregateDsl {
+	return d.histogramOptions(func(o *HistogramOptions) {
+		o.Format = format
+	})
+}
+
+// AggregateRange is one range of the Range, DateRange, IpRange and
+// GeoDistance aggregations, From is inclusive and To exclusive, leave either
+// nil for an open range
+type AggregateRange struct {
+	Key  string      `json:"key,omitempty"`
+	From interface{} `json:"from,omitempty"`
+	To   interface{} `json:"to,omitempty"`
+	// ip_range only, a CIDR mask such as "10.0.0.0/25" instead of From/To
+	Mask string `json:"mask,omitempty"`
+}
+
+type RangeAggregate struct {
+	Field  string           `json:"field"`
+	Format string           `json:"format,omitempty"`
+	Keyed  bool             `json:"keyed,omitempty"`
+	Ranges []AggregateRange `json:"ranges"`
+}
+
+/**
+ * Range buckets docs by the ranges of the field, keyed returns the buckets
+ * in an object keyed by the range keys instead of an array
+ *
+ * Aggregate("price_ranges").Range("price", false,
+ *   AggregateRange{To: 50},
+ *   AggregateRange{From: 50, To: 100},
+ *   AggregateRange{From: 100},
+ * )
+ */
+func (d *AggregateDsl) Range(field string, keyed bool, ranges ...AggregateRange) *AggregateDsl {
+	d.Type = RangeAggregate{Field: field, Keyed: keyed, Ranges: ranges}
+	d.TypeName = "range"
+	return d
+}
+
+// DateRange is a Range of dates, From and To may be date math ("now-10M/M"),
+// format is the date format of the bounds and of the returned keys
+func (d *AggregateDsl) DateRange(field, format string, keyed bool, ranges ...AggregateRange) *AggregateDsl {
+	d.Type = RangeAggregate{Field: field, Format: format, Keyed: keyed, Ranges: ranges}
+	d.TypeName = "date_range"
+	return d
+}
+
+// IpRange is a Range of ip addresses, either From/To or a Mask
+func (d *AggregateDsl) IpRange(field string, keyed bool, ranges ...AggregateRange) *AggregateDsl {
+	d.Type = RangeAggregate{Field: field, Keyed: keyed, Ranges: ranges}
+	d.TypeName = "ip_range"
+	return d
+}
+
+type NamedFiltersAggregate struct {
+	Filters map[string]*FilterOp `json:"filters"`
+}
+
+// NamedFilters creates a bucket per filter, keyed by the name
+func (d *AggregateDsl) NamedFilters(filters map[string]*FilterOp) *AggregateDsl {
+	d.Type = NamedFiltersAggregate{Filters: filters}
+	d.TypeName = "filters"
+	return d
+}
+
+type PathAggregate struct {
+	Path string `json:"path,omitempty"`
+}
+
+// Nested aggregates the nested objects at path, use sub-aggregations on the
+// nested fields
+func (d *AggregateDsl) Nested(path string) *AggregateDsl {
+	d.Type = PathAggregate{Path: path}
+	d.TypeName = "nested"
+	return d
+}
+
+// ReverseNested joins back from nested objects to the docs of path, or the
+// root docs if path is empty, inside a Nested aggregation
+func (d *AggregateDsl) ReverseNested(path string) *AggregateDsl {
+	d.Type = PathAggregate{Path: path}
+	d.TypeName = "reverse_nested"
+	return d
+}
+
+type ChildrenAggregate struct {
+	Type string `json:"type"`
+}
+
+// Children aggregates the child docs of the type of the parent docs in the
+// bucket
+func (d *AggregateDsl) Children(_type string) *AggregateDsl {
+	d.Type = ChildrenAggregate{Type: _type}
+	d.TypeName = "children"
+	return d
+}
+
+type GeoDistanceAggregate struct {
+	Field        string           `json:"field"`
+	Origin       GeoPoint         `json:"origin"`
+	Unit         string           `json:"unit,omitempty"`
+	DistanceType string           `json:"distance_type,omitempty"`
+	Keyed        bool             `json:"keyed,omitempty"`
+	Ranges       []AggregateRange `json:"ranges"`
+}
+
+/**
+ * GeoDistance buckets docs by the distance of the geo_point field from the
+ * origin, unit is the unit of the ranges, m by default
+ *
+ * Aggregate("rings").GeoDistance("location", GeoLocation{Latitude: 52.37, Longitude: 4.89}, "km",
+ *   AggregateRange{To: 100},
+ *   AggregateRange{From: 100, To: 300},
+ * )
+ */
+func (d *AggregateDsl) GeoDistance(field string, origin GeoPoint, unit string, ranges ...AggregateRange) *AggregateDsl {
+	d.Type = GeoDistanceAggregate{Field: field, Origin: origin, Unit: unit, Ranges: ranges}
+	d.TypeName = "geo_distance"
+	return d
+}
+
+type GeohashGridAggregate struct {
+	Field     string `json:"field"`
+	Precision int    `json:"precision,omitempty"`
+	Size      int    `json:"size,omitempty"`
+	ShardSize int    `json:"shard_size,omitempty"`
+}
+
+// GeohashGrid buckets docs by the geohash cell of the geo_point field,
+// precision is the geohash length, 1 to 12 (default 5), and size the max
+// number of buckets returned, 0 for the default
+func (d *AggregateDsl) GeohashGrid(field string, precision, size int) *AggregateDsl {
+	d.Type = GeohashGridAggregate{Field: field, Precision: precision, Size: size}
+	d.TypeName = "geohash_grid"
+	return d
+}
+
 func (d *AggregateDsl) MarshalJSON() ([]byte, error) {
+	if err := validateBucketsPaths(d.AggregatesVal); err != nil {
+		return nil, err
+	}
 	return json.Marshal(d.toMap())
 }
 
@@ -154,6 +154,307 @@ func TestAggregateFilter(t *testing.T) {
 	)
 }
 
+func TestAggregateRanges(t *testing.T) {
+
+	price := Aggregate("price_ranges").Range("price", true,
+		AggregateRange{Key: "cheap", To: 50},
+		AggregateRange{From: 50, To: 100},
+		AggregateRange{From: 100},
+	)
+	dates := Aggregate("range").DateRange("date", "MM-yyy", false,
+		AggregateRange{To: "now-10M/M"},
+		AggregateRange{From: "now-10M/M"},
+	)
+	ips := Aggregate("ip_ranges").IpRange("ip", false,
+		AggregateRange{To: "10.0.0.5"},
+		AggregateRange{Mask: "10.0.0.0/25"},
+	)
+	rings := Aggregate("rings").GeoDistance("location", GeoLocation{Latitude: 52.5, Longitude: 4.5}, "km",
+		AggregateRange{To: 100},
+		AggregateRange{From: 100, To: 300},
+	)
+	grid := Aggregate("grid").GeohashGrid("location", 3, 100)
+
+	qry := Search("github").Aggregates(price, dates, ips, rings, grid)
+
+	marshaled, err := json.MarshalIndent(qry.AggregatesVal, "", "  ")
+	if err != nil {
+		t.Errorf("Failed to marshal AggregatesVal: %s", err.Error())
+		return
+	}
+
+	assertJsonMatch(
+		t,
+		marshaled,
+		[]byte(`
+	{
+		"price_ranges": {
+			"range": {
+				"field": "price",
+				"keyed": true,
+				"ranges": [
+					{ "key": "cheap", "to": 50 },
+					{ "from": 50, "to": 100 },
+					{ "from": 100 }
+				]
+			}
+		},
+		"range": {
+			"date_range": {
+				"field": "date",
+				"format": "MM-yyy",
+				"ranges": [
+					{ "to": "now-10M/M" },
+					{ "from": "now-10M/M" }
+				]
+			}
+		},
+		"ip_ranges": {
+			"ip_range": {
+				"field": "ip",
+				"ranges": [
+					{ "to": "10.0.0.5" },
+					{ "mask": "10.0.0.0/25" }
+				]
+			}
+		},
+		"rings": {
+			"geo_distance": {
+				"field": "location",
+				"origin": { "lat": 52.5, "lon": 4.5 },
+				"unit": "km",
+				"ranges": [
+					{ "to": 100 },
+					{ "from": 100, "to": 300 }
+				]
+			}
+		},
+		"grid": {
+			"geohash_grid": { "field": "location", "precision": 3, "size": 100 }
+		}
+	}
+	`),
+	)
+}
+
+func TestAggregateJoins(t *testing.T) {
+
+	messages := Aggregate("messages").NamedFilters(map[string]*FilterOp{
+		"errors":   Filter().Term("body", "error"),
+		"warnings": Filter().Term("body", "warning"),
+	})
+
+	comments := Aggregate("comments").Nested("comments")
+	comments.Aggregates(
+		Aggregate("top_usernames").Terms("comments.username").Aggregates(
+			Aggregate("comment_to_issue").ReverseNested("").Aggregates(
+				Aggregate("top_tags").Terms("tags"),
+			),
+		),
+	)
+
+	answers := Aggregate("answers").Children("answer")
+	answers.Aggregates(Aggregate("top_names").Terms("owner.display_name"))
+
+	qry := Search("github").Aggregates(messages, comments, answers)
+
+	marshaled, err := json.MarshalIndent(qry.AggregatesVal, "", "  ")
+	if err != nil {
+		t.Errorf("Failed to marshal AggregatesVal: %s", err.Error())
+		return
+	}
+
+	assertJsonMatch(
+		t,
+		marshaled,
+		[]byte(`
+	{
+		"messages": {
+			"filters": {
+				"filters": {
+					"errors": { "term": { "body": "error" } },
+					"warnings": { "term": { "body": "warning" } }
+				}
+			}
+		},
+		"comments": {
+			"nested": { "path": "comments" },
+			"aggregations": {
+				"top_usernames": {
+					"terms": { "field": "comments.username" },
+					"aggregations": {
+						"comment_to_issue": {
+							"reverse_nested": {},
+							"aggregations": {
+								"top_tags": { "terms": { "field": "tags" } }
+							}
+						}
+					}
+				}
+			}
+		},
+		"answers": {
+			"children": { "type": "answer" },
+			"aggregations": {
+				"top_names": { "terms": { "field": "owner.display_name" } }
+			}
+		}
+	}
+	`),
+	)
+}
+
+func TestAggregateMetrics(t *testing.T) {
+
+	tags := Aggregate("top_tags").Terms("tags")
+	tags.Aggregates(
+		Aggregate("latest").TopHits(1, []*SortDsl{Sort("date").Desc()}, []string{"title"}),
+		Aggregate("avg_grade").Avg("").Script("doc['grade'].value * correction",
+			map[string]interface{}{"correction": 1.2}),
+		Aggregate("max_price").Max("price").Script("_value * 2", nil),
+	)
+	ranks := Aggregate("load_time_outlier").PercentileRanks("load_time", 15, 30)
+	bounds := Aggregate("viewport").GeoBounds("location", false)
+	centroid := Aggregate("centroid").GeoCentroid("location")
+	profit := Aggregate("profit").ScriptedMetric(
+		"_agg['transactions'] = []",
+		"_agg.transactions.add(doc['amount'].value)",
+		"",
+		"",
+		map[string]interface{}{"_agg": map[string]interface{}{}},
+	)
+
+	qry := Search("github").Aggregates(tags, ranks, bounds, centroid, profit)
+
+	marshaled, err := json.MarshalIndent(qry.AggregatesVal, "", "  ")
+	if err != nil {
+		t.Errorf("Failed to marshal AggregatesVal: %s", err.Error())
+		return
+	}
+
+	assertJsonMatch(
+		t,
+		marshaled,
+		[]byte(`
+	{
+		"top_tags": {
+			"terms": { "field": "tags" },
+			"aggregations": {
+				"latest": {
+					"top_hits": {
+						"size": 1,
+						"sort": [ { "date": "desc" } ],
+						"_source": { "include": [ "title" ] }
+					}
+				},
+				"avg_grade": {
+					"avg": {
+						"script": "doc['grade'].value * correction",
+						"params": { "correction": 1.2 }
+					}
+				},
+				"max_price": {
+					"max": { "field": "price", "script": "_value * 2" }
+				}
+			}
+		},
+		"load_time_outlier": {
+			"percentile_ranks": { "field": "load_time", "values": [ 15, 30 ] }
+		},
+		"viewport": {
+			"geo_bounds": { "field": "location", "wrap_longitude": false }
+		},
+		"centroid": {
+			"geo_centroid": { "field": "location" }
+		},
+		"profit": {
+			"scripted_metric": {
+				"init_script": "_agg['transactions'] = []",
+				"map_script": "_agg.transactions.add(doc['amount'].value)",
+				"params": { "_agg": {} }
+			}
+		}
+	}
+	`),
+	)
+}
+
+func TestAggregateBucketOptions(t *testing.T) {
+
+	genders := Aggregate("genders").TermsWithSize("gender", 5).
+		Order("avg_height", "desc").Order("_term", "asc").
+		MinDocCount(0).ShardSize(20).
+		Include("m.*").Exclude([]string{"unknown"}).
+		CollectMode("breadth_first").MissingValue("N/A")
+	genders.Aggregates(Aggregate("avg_height").Avg("height"))
+
+	byScript := Aggregate("by_script").Terms("").Script("doc['genre'].value", nil).Order("_count", "asc")
+
+	prices := Aggregate("prices").Histogram("price", 50).
+		MinDocCount(0).ExtendedBounds(0, 500).Offset(10).Order("_key", "desc")
+
+	perDay := Aggregate("per_day").DateHistogram("date", "day").
+		MinDocCount(0).ExtendedBounds("2015-06-01", "2015-06-30").
+		Offset("+6h").TimeZone("America/Los_Angeles").Format("yyyy-MM-dd")
+
+	qry := Search("github").Aggregates(genders, byScript, prices, perDay)
+
+	marshaled, err := json.MarshalIndent(qry.AggregatesVal, "", "  ")
+	if err != nil {
+		t.Errorf("Failed to marshal AggregatesVal: %s", err.Error())
+		return
+	}
+
+	assertJsonMatch(
+		t,
+		marshaled,
+		[]byte(`
+	{
+		"genders": {
+			"terms": {
+				"field": "gender",
+				"size": 5,
+				"order": [ { "avg_height": "desc" }, { "_term": "asc" } ],
+				"min_doc_count": 0,
+				"shard_size": 20,
+				"include": "m.*",
+				"exclude": [ "unknown" ],
+				"collect_mode": "breadth_first",
+				"missing": "N/A"
+			},
+			"aggregations": {
+				"avg_height": { "avg": { "field": "height" } }
+			}
+		},
+		"by_script": {
+			"terms": { "script": "doc['genre'].value", "order": { "_count": "asc" } }
+		},
+		"prices": {
+			"histogram": {
+				"field": "price",
+				"interval": 50,
+				"order": { "_key": "desc" },
+				"min_doc_count": 0,
+				"extended_bounds": { "min": 0, "max": 500 },
+				"offset": 10
+			}
+		},
+		"per_day": {
+			"date_histogram": {
+				"field": "date",
+				"interval": "day",
+				"min_doc_count": 0,
+				"extended_bounds": { "min": "2015-06-01", "max": "2015-06-30" },
+				"offset": "+6h",
+				"time_zone": "America/Los_Angeles",
+				"format": "yyyy-MM-dd"
+			}
+		}
+	}
+	`),
+	)
+}
+
 func assertJsonMatch(t *testing.T, match, expected []byte) {
 	var m interface{}
 	var e interface{}
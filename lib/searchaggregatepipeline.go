@@ -0,0 +1,219 @@
+// Copyright 2013 Matthew Baird
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//     http://www.apache.org/licenses/LICENSE-2.0
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package elastigo
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// PipelineAggregate holds the options of the pipeline aggregations, which
+// aggregate the output of other aggregations named by BucketsPath rather
+// than docs.  Paths are relative to the aggregations registered alongside
+// the pipeline, "sales_per_month>sales", "stats.avg" or "_count", and are
+// checked when the aggregations are marshalled.
+// http://www.elastic.co/guide/en/elasticsearch/reference/2.x/search-aggregations-pipeline.html
+type PipelineAggregate struct {
+	// a path string, or map[string]string of script variables to paths for
+	// bucket_script and bucket_selector
+	BucketsPath interface{}            `json:"buckets_path"`
+	GapPolicy   string                 `json:"gap_policy,omitempty"`
+	Format      string                 `json:"format,omitempty"`
+	Unit        string                 `json:"unit,omitempty"`
+	Model       string                 `json:"model,omitempty"`
+	Window      int                    `json:"window,omitempty"`
+	Predict     int                    `json:"predict,omitempty"`
+	Settings    map[string]interface{} `json:"settings,omitempty"`
+	Script      string                 `json:"script,omitempty"`
+	Params      map[string]interface{} `json:"params,omitempty"`
+}
+
+func (d *AggregateDsl) pipeline(typeName string, p PipelineAggregate) *AggregateDsl {
+	d.Type = p
+	d.TypeName = typeName
+	return d
+}
+
+/**
+ * Derivative of a metric of the buckets of its parent histogram
+ *
+ * Aggregate("sales_per_month").DateHistogram("date", "month").Aggregates(
+ *   Aggregate("sales").Sum("price"),
+ *   Aggregate("sales_deriv").Derivative("sales"),
+ * )
+ */
+func (d *AggregateDsl) Derivative(bucketsPath string) *AggregateDsl {
+	return d.pipeline("derivative", PipelineAggregate{BucketsPath: bucketsPath})
+}
+
+// MovingAvg smooths a metric of the buckets of its parent histogram over a
+// window of buckets, model is simple, linear, ewma, holt or holt_winters
+func (d *AggregateDsl) MovingAvg(bucketsPath, model string, window int) *AggregateDsl {
+	return d.pipeline("moving_avg", PipelineAggregate{BucketsPath: bucketsPath, Model: model, Window: window})
+}
+
+// CumulativeSum of a metric of the buckets of its parent histogram
+func (d *AggregateDsl) CumulativeSum(bucketsPath string) *AggregateDsl {
+	return d.pipeline("cumulative_sum", PipelineAggregate{BucketsPath: bucketsPath})
+}
+
+/**
+ * BucketScript computes a metric per bucket of its parent from the
+ * bucketsPaths, keyed by the script variable
+ *
+ * Aggregate("t-shirt-percentage").BucketScript(
+ *   map[string]string{"tShirtSales": "t-shirts>sales", "totalSales": "total_sales"},
+ *   "tShirtSales / totalSales * 100",
+ * )
+ */
+func (d *AggregateDsl) BucketScript(bucketsPaths map[string]string, script string) *AggregateDsl {
+	return d.pipeline("bucket_script", PipelineAggregate{BucketsPath: bucketsPaths, Script: script})
+}
+
+// BucketSelector drops the buckets of its parent for which the script, using
+// the bucketsPaths variables, is false
+func (d *AggregateDsl) BucketSelector(bucketsPaths map[string]string, script string) *AggregateDsl {
+	return d.pipeline("bucket_selector", PipelineAggregate{BucketsPath: bucketsPaths, Script: script})
+}
+
+// AvgBucket averages a metric over the buckets of a sibling aggregation,
+// "sales_per_month>sales"
+func (d *AggregateDsl) AvgBucket(bucketsPath string) *AggregateDsl {
+	return d.pipeline("avg_bucket", PipelineAggregate{BucketsPath: bucketsPath})
+}
+
+// MaxBucket finds the buckets of a sibling aggregation with the max metric
+func (d *AggregateDsl) MaxBucket(bucketsPath string) *AggregateDsl {
+	return d.pipeline("max_bucket", PipelineAggregate{BucketsPath: bucketsPath})
+}
+
+// SumBucket sums a metric over the buckets of a sibling aggregation
+func (d *AggregateDsl) SumBucket(bucketsPath string) *AggregateDsl {
+	return d.pipeline("sum_bucket", PipelineAggregate{BucketsPath: bucketsPath})
+}
+
+// StatsBucket computes stats of a metric over the buckets of a sibling
+// aggregation
+func (d *AggregateDsl) StatsBucket(bucketsPath string) *AggregateDsl {
+	return d.pipeline("stats_bucket", PipelineAggregate{BucketsPath: bucketsPath})
+}
+
+// GapPolicy sets how a pipeline aggregate treats missing buckets, skip
+// (default) or insert_zeros
+func (d *AggregateDsl) GapPolicy(policy string) *AggregateDsl {
+	if p, ok := d.Type.(PipelineAggregate); ok {
+		p.GapPolicy = policy
+		d.Type = p
+	}
+	return d
+}
+
+// Unit sets the time unit of a derivative, "day" for a per day rate
+func (d *AggregateDsl) Unit(unit string) *AggregateDsl {
+	if p, ok := d.Type.(PipelineAggregate); ok {
+		p.Unit = unit
+		d.Type = p
+	}
+	return d
+}
+
+// Predict sets the number of buckets a moving_avg predicts past the end
+func (d *AggregateDsl) Predict(buckets int) *AggregateDsl {
+	if p, ok := d.Type.(PipelineAggregate); ok {
+		p.Predict = buckets
+		d.Type = p
+	}
+	return d
+}
+
+// ModelSettings sets the model settings of a moving_avg, such as alpha
+func (d *AggregateDsl) ModelSettings(settings map[string]interface{}) *AggregateDsl {
+	if p, ok := d.Type.(PipelineAggregate); ok {
+		p.Settings = settings
+		d.Type = p
+	}
+	return d
+}
+
+// Check the buckets_path of the pipeline aggregates in aggs, and below, name
+// aggregates registered alongside them
+func validateBucketsPaths(aggs map[string]*AggregateDsl) error {
+	names := make([]string, 0, len(aggs))
+	for name := range aggs {
+		names = append(names, name)
+	}
+	// report the same error each time
+	sort.Strings(names)
+
+	for _, name := range names {
+		agg := aggs[name]
+		if p, ok := agg.Type.(PipelineAggregate); ok {
+			var paths []string
+			switch bp := p.BucketsPath.(type) {
+			case string:
+				paths = []string{bp}
+			case map[string]string:
+				for _, path := range bp {
+					paths = append(paths, path)
+				}
+				sort.Strings(paths)
+			}
+			if len(paths) == 0 {
+				return fmt.Errorf("aggregation %q has no buckets_path", agg.Name)
+			}
+			for _, path := range paths {
+				if err := resolveBucketsPath(aggs, agg, path); err != nil {
+					return fmt.Errorf("aggregation %q: %v", agg.Name, err)
+				}
+			}
+		}
+		if err := validateBucketsPaths(agg.AggregatesVal); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Follow AGG_NAME[>AGG_NAME]*[.METRIC] through the registered aggregates
+func resolveBucketsPath(aggs map[string]*AggregateDsl, from *AggregateDsl, path string) error {
+	if path == "" {
+		return fmt.Errorf("empty buckets_path")
+	}
+	parts := strings.Split(path, ">")
+	for i, part := range parts {
+		last := i == len(parts)-1
+		name := part
+		// a bucket key, sale_type['hat']
+		if idx := strings.Index(name, "["); idx >= 0 {
+			name = name[:idx]
+		}
+		if last {
+			if name == "_count" || name == "_key" {
+				return nil
+			}
+			// a metric of a multi value aggregate, stats.avg, unless the
+			// whole name is registered
+			if _, ok := aggs[name]; !ok {
+				if idx := strings.Index(name, "."); idx >= 0 {
+					name = name[:idx]
+				}
+			}
+		}
+		agg, ok := aggs[name]
+		if !ok || agg == from {
+			return fmt.Errorf("buckets_path %q: no aggregation named %q", path, name)
+		}
+		aggs = agg.AggregatesVal
+	}
+	return nil
+}
@@ -0,0 +1,148 @@
+// Copyright 2013 Matthew Baird
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//     http://www.apache.org/licenses/LICENSE-2.0
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package elastigo
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestAggregatePipelines(t *testing.T) {
+
+	perMonth := Aggregate("sales_per_month").DateHistogram("date", "month")
+	perMonth.Aggregates(
+		Aggregate("sales").Sum("price"),
+		Aggregate("tshirts").Filter(Filter().Term("type", "t-shirt")).Aggregates(
+			Aggregate("sales").Sum("price"),
+		),
+		Aggregate("sales_deriv").Derivative("sales").Unit("day"),
+		Aggregate("sales_avg").MovingAvg("sales", "holt", 5).Predict(3).
+			ModelSettings(map[string]interface{}{"alpha": 0.5}).GapPolicy("insert_zeros"),
+		Aggregate("sales_total").CumulativeSum("sales"),
+		Aggregate("tshirt_pct").BucketScript(
+			map[string]string{"tShirtSales": "tshirts>sales", "totalSales": "sales"},
+			"tShirtSales / totalSales * 100",
+		),
+		Aggregate("busy_months").BucketSelector(map[string]string{"count": "_count"}, "count > 10"),
+	)
+
+	qry := Search("github").Aggregates(
+		perMonth,
+		Aggregate("avg_monthly_sales").AvgBucket("sales_per_month>sales"),
+		Aggregate("max_monthly_sales").MaxBucket("sales_per_month>sales"),
+		Aggregate("sum_monthly_sales").SumBucket("sales_per_month>sales"),
+		Aggregate("stats_monthly_sales").StatsBucket("sales_per_month>_count"),
+	)
+
+	marshaled, err := json.MarshalIndent(qry.AggregatesVal, "", "  ")
+	if err != nil {
+		t.Errorf("Failed to marshal AggregatesVal: %s", err.Error())
+		return
+	}
+	if err := validateBucketsPaths(qry.AggregatesVal); err != nil {
+		t.Errorf("Unexpected buckets_path error: %s", err.Error())
+	}
+
+	assertJsonMatch(
+		t,
+		marshaled,
+		[]byte(`
+	{
+		"sales_per_month": {
+			"date_histogram": { "field": "date", "interval": "month" },
+			"aggregations": {
+				"sales": { "sum": { "field": "price" } },
+				"tshirts": {
+					"filter": { "term": { "type": "t-shirt" } },
+					"aggregations": {
+						"sales": { "sum": { "field": "price" } }
+					}
+				},
+				"sales_deriv": { "derivative": { "buckets_path": "sales", "unit": "day" } },
+				"sales_avg": {
+					"moving_avg": {
+						"buckets_path": "sales",
+						"gap_policy": "insert_zeros",
+						"model": "holt",
+						"window": 5,
+						"predict": 3,
+						"settings": { "alpha": 0.5 }
+					}
+				},
+				"sales_total": { "cumulative_sum": { "buckets_path": "sales" } },
+				"tshirt_pct": {
+					"bucket_script": {
+						"buckets_path": { "tShirtSales": "tshirts>sales", "totalSales": "sales" },
+						"script": "tShirtSales / totalSales * 100"
+					}
+				},
+				"busy_months": {
+					"bucket_selector": {
+						"buckets_path": { "count": "_count" },
+						"script": "count > 10"
+					}
+				}
+			}
+		},
+		"avg_monthly_sales": { "avg_bucket": { "buckets_path": "sales_per_month>sales" } },
+		"max_monthly_sales": { "max_bucket": { "buckets_path": "sales_per_month>sales" } },
+		"sum_monthly_sales": { "sum_bucket": { "buckets_path": "sales_per_month>sales" } },
+		"stats_monthly_sales": { "stats_bucket": { "buckets_path": "sales_per_month>_count" } }
+	}
+	`),
+	)
+}
+
+func TestAggregatePipelinePaths(t *testing.T) {
+
+	valid := []string{"sales", "stats.avg", "_count", "per_type['hat']>sales", "per_type>stats.max"}
+	invalid := []string{"", "sale", "per_type>sale", "nope.avg", "sales>sales", "self"}
+
+	for _, path := range valid {
+		aggs := Aggregate("sales_per_month").DateHistogram("date", "month").Aggregates(
+			Aggregate("sales").Sum("price"),
+			Aggregate("stats").Stats("price"),
+			Aggregate("per_type").Terms("type").Aggregates(
+				Aggregate("sales").Sum("price"),
+				Aggregate("stats").Stats("price"),
+			),
+			Aggregate("self").Derivative(path),
+		)
+		if _, err := json.Marshal(aggs); err != nil {
+			t.Errorf("Expected buckets_path %q to be valid: %s", path, err.Error())
+		}
+	}
+
+	for _, path := range invalid {
+		aggs := Aggregate("sales_per_month").DateHistogram("date", "month").Aggregates(
+			Aggregate("sales").Sum("price"),
+			Aggregate("per_type").Terms("type").Aggregates(
+				Aggregate("sales").Sum("price"),
+			),
+			Aggregate("self").Derivative(path),
+		)
+		_, err := json.Marshal(aggs)
+		if err == nil || !strings.Contains(err.Error(), `aggregation "self"`) {
+			t.Errorf("Expected buckets_path %q to be invalid, got %v", path, err)
+		}
+	}
+
+	// sibling pipelines at the top level are checked before searching
+	_, err := Search("github").Aggregates(
+		Aggregate("sales_per_month").DateHistogram("date", "month"),
+		Aggregate("avg_monthly_sales").AvgBucket("sales_per_month>sales"),
+	).Bytes(nil)
+	if err == nil || !strings.Contains(err.Error(), `no aggregation named "sales"`) {
+		t.Errorf("Expected a buckets_path error, got %v", err)
+	}
+}
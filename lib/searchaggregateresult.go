@@ -0,0 +1,213 @@
+// Copyright 2013 Matthew Baird
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//     http://www.apache.org/licenses/LICENSE-2.0
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package elastigo
+
+import (
+	"encoding/json"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Aggregations holds the raw aggregations of a SearchResult or bucket, it
+// is still a []byte for callers decoding it themselves.  The accessors decode
+// the named aggregation, reporting false if it is missing or doesn't have
+// the shape asked for.
+//
+//	if tags, ok := out.Aggregations.Terms("tags"); ok {
+//		for _, bucket := range tags.Buckets {
+//			authors, _ := bucket.Aggregations.Cardinality("authors")
+//		}
+//	}
+type Aggregations json.RawMessage
+
+func (a Aggregations) MarshalJSON() ([]byte, error) {
+	if len(a) == 0 {
+		return []byte("null"), nil
+	}
+	return a, nil
+}
+
+func (a *Aggregations) UnmarshalJSON(data []byte) error {
+	*a = append((*a)[0:0], data...)
+	return nil
+}
+
+// Decode the named aggregation into v if it has the key, the one field every
+// aggregation of v's shape has
+func (a Aggregations) get(name, key string, v interface{}) bool {
+	if len(a) == 0 {
+		return false
+	}
+	var aggs map[string]json.RawMessage
+	if err := json.Unmarshal(a, &aggs); err != nil {
+		return false
+	}
+	raw, ok := aggs[name]
+	if !ok || string(raw) == "null" {
+		return false
+	}
+	var fields map[string]json.RawMessage
+	if json.Unmarshal(raw, &fields) != nil {
+		return false
+	}
+	if _, ok := fields[key]; !ok {
+		return false
+	}
+	return json.Unmarshal(raw, v) == nil
+}
+
+// Terms returns a terms aggregation
+func (a Aggregations) Terms(name string) (*TermsAggregation, bool) {
+	agg := new(TermsAggregation)
+	return agg, a.get(name, "buckets", agg)
+}
+
+// DateHistogram returns a date_histogram aggregation, see
+// AggregationBucket.Time for the bucket keys
+func (a Aggregations) DateHistogram(name string) (*BucketsAggregation, bool) {
+	agg := new(BucketsAggregation)
+	return agg, a.get(name, "buckets", agg)
+}
+
+// Histogram returns a histogram aggregation
+func (a Aggregations) Histogram(name string) (*BucketsAggregation, bool) {
+	agg := new(BucketsAggregation)
+	return agg, a.get(name, "buckets", agg)
+}
+
+// Stats returns a stats or extended_stats aggregation
+func (a Aggregations) Stats(name string) (*StatsAggregation, bool) {
+	agg := new(StatsAggregation)
+	return agg, a.get(name, "count", agg)
+}
+
+// Percentiles returns a percentiles aggregation
+func (a Aggregations) Percentiles(name string) (*PercentilesAggregation, bool) {
+	agg := new(PercentilesAggregation)
+	return agg, a.get(name, "values", agg)
+}
+
+// Value returns a single value metric aggregation, min, max, sum, avg,
+// value_count or cardinality
+func (a Aggregations) Value(name string) (*ValueAggregation, bool) {
+	agg := new(ValueAggregation)
+	return agg, a.get(name, "value", agg)
+}
+
+// Cardinality returns a cardinality aggregation
+func (a Aggregations) Cardinality(name string) (*ValueAggregation, bool) {
+	return a.Value(name)
+}
+
+// Filter returns a single bucket aggregation, filter, global, missing,
+// nested or reverse_nested
+func (a Aggregations) Filter(name string) (*AggregationBucket, bool) {
+	agg := new(AggregationBucket)
+	return agg, a.get(name, "doc_count", agg)
+}
+
+// TopHits returns a top_hits aggregation
+func (a Aggregations) TopHits(name string) (*TopHitsAggregation, bool) {
+	agg := new(TopHitsAggregation)
+	return agg, a.get(name, "hits", agg)
+}
+
+type TermsAggregation struct {
+	DocCountErrorUpperBound int64               `json:"doc_count_error_upper_bound"`
+	SumOtherDocCount        int64               `json:"sum_other_doc_count"`
+	Buckets                 []AggregationBucket `json:"buckets"`
+}
+
+type BucketsAggregation struct {
+	Buckets []AggregationBucket `json:"buckets"`
+}
+
+// AggregationBucket is one bucket of a bucket aggregation, or a single
+// bucket aggregation.  Aggregations holds its sub-aggregations.
+type AggregationBucket struct {
+	// a string or float64, the date in milliseconds for date_histogram
+	Key          interface{}  `json:"key,omitempty"`
+	KeyAsString  string       `json:"key_as_string,omitempty"`
+	DocCount     int64        `json:"doc_count"`
+	Aggregations Aggregations `json:"-"`
+}
+
+func (b *AggregationBucket) UnmarshalJSON(data []byte) error {
+	type bucket AggregationBucket
+	var fields bucket
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return err
+	}
+	*b = AggregationBucket(fields)
+	// sub-aggregations are keyed by name alongside the bucket fields
+	b.Aggregations = Aggregations(append([]byte(nil), data...))
+	return nil
+}
+
+// KeyString is the key as a string, key_as_string if there is one
+func (b *AggregationBucket) KeyString() string {
+	if b.KeyAsString != "" {
+		return b.KeyAsString
+	}
+	switch key := b.Key.(type) {
+	case string:
+		return key
+	case float64:
+		return strconv.FormatFloat(key, 'f', -1, 64)
+	}
+	return ""
+}
+
+// Time is the key of a date_histogram bucket
+func (b *AggregationBucket) Time() time.Time {
+	millis, _ := b.Key.(float64)
+	return time.Unix(0, int64(millis)*int64(time.Millisecond)).UTC()
+}
+
+// StatsAggregation values are nil when there were no values
+type StatsAggregation struct {
+	Count int64    `json:"count"`
+	Min   *float64 `json:"min"`
+	Max   *float64 `json:"max"`
+	Avg   *float64 `json:"avg"`
+	Sum   *float64 `json:"sum"`
+	// extended_stats only
+	SumOfSquares *float64 `json:"sum_of_squares,omitempty"`
+	Variance     *float64 `json:"variance,omitempty"`
+	StdDeviation *float64 `json:"std_deviation,omitempty"`
+}
+
+type PercentilesAggregation struct {
+	// keyed by the percentile, "99.0"
+	Values map[string]float64 `json:"values"`
+}
+
+// Percentile returns the value of the percentile, 99 or 99.9
+func (p *PercentilesAggregation) Percentile(percent float64) (float64, bool) {
+	key := strconv.FormatFloat(percent, 'f', -1, 64)
+	if !strings.Contains(key, ".") {
+		key += ".0"
+	}
+	val, ok := p.Values[key]
+	return val, ok
+}
+
+// ValueAggregation Value is nil when there were no values
+type ValueAggregation struct {
+	Value         *float64 `json:"value"`
+	ValueAsString string   `json:"value_as_string,omitempty"`
+}
+
+type TopHitsAggregation struct {
+	Hits Hits `json:"hits"`
+}
@@ -0,0 +1,128 @@
+// Copyright 2013 Matthew Baird
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//     http://www.apache.org/licenses/LICENSE-2.0
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package elastigo
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/bmizerany/assert"
+)
+
+const aggregationsResponse = `{
+	"took": 3,
+	"hits": { "total": 12, "hits": [] },
+	"aggregations": {
+		"tags": {
+			"doc_count_error_upper_bound": 0,
+			"sum_other_doc_count": 4,
+			"buckets": [
+				{
+					"key": "go",
+					"doc_count": 5,
+					"authors": { "value": 3 },
+					"per_day": {
+						"buckets": [
+							{ "key_as_string": "2015-06-01", "key": 1433116800000, "doc_count": 2 },
+							{ "key_as_string": "2015-06-02", "key": 1433203200000, "doc_count": 3 }
+						]
+					}
+				},
+				{ "key": "elasticsearch", "doc_count": 3, "authors": { "value": 1 } }
+			]
+		},
+		"goals": { "count": 12, "min": 0, "max": 51, "avg": 20.5, "sum": 246 },
+		"empty": { "count": 0, "min": null, "max": null, "avg": null, "sum": null },
+		"load_time": { "values": { "50.0": 120, "99.0": 430.5, "99.9": 512 } },
+		"recent": {
+			"doc_count": 7,
+			"latest": {
+				"hits": {
+					"total": 7,
+					"hits": [ { "_index": "blog", "_type": "post", "_id": "3", "_score": 1, "_source": { "title": "hi" } } ]
+				}
+			}
+		}
+	}
+}`
+
+func TestAggregationsResult(t *testing.T) {
+	var out SearchResult
+	err := json.Unmarshal([]byte(aggregationsResponse), &out)
+	assert.Equal(t, nil, err)
+
+	tags, ok := out.Aggregations.Terms("tags")
+	assert.T(t, ok)
+	assert.Equal(t, int64(4), tags.SumOtherDocCount)
+	assert.Equal(t, 2, len(tags.Buckets))
+	assert.Equal(t, "go", tags.Buckets[0].KeyString())
+	assert.Equal(t, int64(5), tags.Buckets[0].DocCount)
+
+	authors, ok := tags.Buckets[0].Aggregations.Cardinality("authors")
+	assert.T(t, ok)
+	assert.Equal(t, float64(3), *authors.Value)
+
+	perDay, ok := tags.Buckets[0].Aggregations.DateHistogram("per_day")
+	assert.T(t, ok)
+	assert.Equal(t, 2, len(perDay.Buckets))
+	assert.Equal(t, "2015-06-02", perDay.Buckets[1].KeyString())
+	assert.Equal(t, time.Date(2015, 6, 2, 0, 0, 0, 0, time.UTC), perDay.Buckets[1].Time())
+
+	_, ok = tags.Buckets[1].Aggregations.DateHistogram("per_day")
+	assert.T(t, !ok)
+
+	goals, ok := out.Aggregations.Stats("goals")
+	assert.T(t, ok)
+	assert.Equal(t, int64(12), goals.Count)
+	assert.Equal(t, float64(51), *goals.Max)
+	assert.Equal(t, float64(20.5), *goals.Avg)
+
+	empty, ok := out.Aggregations.Stats("empty")
+	assert.T(t, ok)
+	assert.T(t, empty.Min == nil)
+
+	loadTime, ok := out.Aggregations.Percentiles("load_time")
+	assert.T(t, ok)
+	p99, ok := loadTime.Percentile(99)
+	assert.T(t, ok)
+	assert.Equal(t, 430.5, p99)
+	p999, _ := loadTime.Percentile(99.9)
+	assert.Equal(t, float64(512), p999)
+
+	recent, ok := out.Aggregations.Filter("recent")
+	assert.T(t, ok)
+	assert.Equal(t, int64(7), recent.DocCount)
+
+	latest, ok := recent.Aggregations.TopHits("latest")
+	assert.T(t, ok)
+	assert.Equal(t, 7, latest.Hits.Total)
+	assert.Equal(t, "3", latest.Hits.Hits[0].Id)
+
+	_, ok = out.Aggregations.Terms("missing")
+	assert.T(t, !ok)
+
+	// an aggregation of another shape isn't decoded
+	_, ok = out.Aggregations.Terms("goals")
+	assert.T(t, !ok)
+	_, ok = out.Aggregations.Histogram("recent")
+	assert.T(t, !ok)
+	_, ok = tags.Buckets[0].Aggregations.Stats("authors")
+	assert.T(t, !ok)
+	_, ok = out.Aggregations.Value("tags")
+	assert.T(t, !ok)
+
+	// the raw json is still available for custom decoding
+	var raw map[string]interface{}
+	assert.Equal(t, nil, json.Unmarshal(out.Aggregations, &raw))
+	assert.Equal(t, 5, len(raw))
+}
@@ -0,0 +1,119 @@
+// Copyright 2013 Matthew Baird
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//     http://www.apache.org/licenses/LICENSE-2.0
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package elastigo
+
+// DisMax creates a blank dis_max query, scoring docs by the best matching of
+// its queries plus TieBreaker times the scores of the others
+// http://www.elastic.co/guide/en/elasticsearch/reference/1.x/query-dsl-dis-max-query.html
+func DisMax(queries ...*QueryDsl) *DisMaxQuery {
+	return &DisMaxQuery{QueriesVal: queries}
+}
+
+type DisMaxQuery struct {
+	QueriesVal    []*QueryDsl `json:"queries"`
+	TieBreakerVal float64     `json:"tie_breaker,omitempty"`
+	BoostVal      float64     `json:"boost,omitempty"`
+}
+
+func (d *DisMaxQuery) Add(queries ...*QueryDsl) *DisMaxQuery {
+	d.QueriesVal = append(d.QueriesVal, queries...)
+	return d
+}
+
+// TieBreaker sets the weight, between 0 and 1, of the queries that aren't
+// the best match
+func (d *DisMaxQuery) TieBreaker(tieBreaker float64) *DisMaxQuery {
+	d.TieBreakerVal = tieBreaker
+	return d
+}
+
+func (d *DisMaxQuery) Boost(boost float64) *DisMaxQuery {
+	d.BoostVal = boost
+	return d
+}
+
+// ConstantScore creates a constant_score query, matching the docs of the
+// filter all with a score of the boost
+// http://www.elastic.co/guide/en/elasticsearch/reference/1.x/query-dsl-constant-score-query.html
+func ConstantScore(filter *FilterOp) *ConstantScoreQuery {
+	return &ConstantScoreQuery{FilterVal: filter}
+}
+
+type ConstantScoreQuery struct {
+	FilterVal *FilterOp `json:"filter,omitempty"`
+	QueryVal  *QueryDsl `json:"query,omitempty"`
+	BoostVal  float64   `json:"boost,omitempty"`
+}
+
+// Query wraps a query instead of a filter, ignoring its scores
+func (c *ConstantScoreQuery) Query(q *QueryDsl) *ConstantScoreQuery {
+	c.FilterVal = nil
+	c.QueryVal = q
+	return c
+}
+
+func (c *ConstantScoreQuery) Boost(boost float64) *ConstantScoreQuery {
+	c.BoostVal = boost
+	return c
+}
+
+// Boosting creates a boosting query, matching the docs of positive and
+// demoting those also matching negative by NegativeBoost
+// http://www.elastic.co/guide/en/elasticsearch/reference/1.x/query-dsl-boosting-query.html
+func Boosting(positive, negative *QueryDsl, negativeBoost float64) *BoostingQuery {
+	return &BoostingQuery{PositiveVal: positive, NegativeVal: negative, NegativeBoostVal: negativeBoost}
+}
+
+type BoostingQuery struct {
+	PositiveVal      *QueryDsl `json:"positive"`
+	NegativeVal      *QueryDsl `json:"negative"`
+	NegativeBoostVal float64   `json:"negative_boost"`
+	BoostVal         float64   `json:"boost,omitempty"`
+}
+
+// NegativeBoost sets the factor, below 1, applied to the score of docs
+// matching the negative query
+func (b *BoostingQuery) NegativeBoost(negativeBoost float64) *BoostingQuery {
+	b.NegativeBoostVal = negativeBoost
+	return b
+}
+
+func (b *BoostingQuery) Boost(boost float64) *BoostingQuery {
+	b.BoostVal = boost
+	return b
+}
+
+// Indices creates an indices query, running the query on the indices and
+// the NoMatch query on any other index searched, which defaults to all
+// http://www.elastic.co/guide/en/elasticsearch/reference/1.x/query-dsl-indices-query.html
+func Indices(query *QueryDsl, indices ...string) *IndicesQuery {
+	return &IndicesQuery{IndicesVal: indices, QueryVal: query}
+}
+
+type IndicesQuery struct {
+	IndicesVal []string  `json:"indices"`
+	QueryVal   *QueryDsl `json:"query"`
+	// either "all", "none" or a *QueryDsl
+	NoMatchQueryVal interface{} `json:"no_match_query,omitempty"`
+}
+
+// NoMatch sets what other indices match, "all" or "none"
+func (i *IndicesQuery) NoMatch(mode string) *IndicesQuery {
+	i.NoMatchQueryVal = mode
+	return i
+}
+
+// NoMatchQuery sets the query run on other indices
+func (i *IndicesQuery) NoMatchQuery(q *QueryDsl) *IndicesQuery {
+	i.NoMatchQueryVal = q
+	return i
+}
@@ -181,6 +181,16 @@ type FilterOp struct {
 	ScriptProp      *ScriptFilter          `json:"script,omitempty"`
 	GeoDistMap      map[string]interface{} `json:"geo_distance,omitempty"`
 	GeoDistRangeMap map[string]interface{} `json:"geo_distance_range,omitempty"`
+	GeoBoxMap       map[string]interface{} `json:"geo_bounding_box,omitempty"`
+	GeoPolygonMap   map[string]interface{} `json:"geo_polygon,omitempty"`
+	GeohashCellMap  map[string]interface{} `json:"geohash_cell,omitempty"`
+	GeoShapeMap     map[string]interface{} `json:"geo_shape,omitempty"`
+	NestedProp      *JoinQuery             `json:"nested,omitempty"`
+	HasChildProp    *JoinQuery             `json:"has_child,omitempty"`
+	HasParentProp   *JoinQuery             `json:"has_parent,omitempty"`
+
+	// the last nested, has_child or has_parent filter set
+	join *JoinQuery
 }
 
 type propertyPathMarker struct {
@@ -232,7 +242,8 @@ type RangeFilter struct {
 	TimeZone string      `json:"time_zone,omitempty"` //Ideally this would be an int
 }
 
-// GeoLocation holds the coordinates for a geo query. Currently hashes are not supported.
+// GeoLocation holds the coordinates for a geo query.  See AsGeohash and
+// AsArray for the other forms, and ParseGeoLocation to read any of them.
 type GeoLocation struct {
 	Latitude  float32 `json:"lat"`
 	Longitude float32 `json:"lon"`
@@ -243,6 +254,17 @@ type GeoLocation struct {
 type GeoField struct {
 	GeoLocation
 	Field string
+	// Point, if set, is sent in place of GeoLocation, for a GeoHash or a
+	// GeoArray, see NewGeoPointField
+	Point GeoPoint `json:"-"`
+}
+
+// The location to send for the field
+func (g GeoField) point() GeoPoint {
+	if g.Point != nil {
+		return g.Point
+	}
+	return g.GeoLocation
 }
 
 // Term will add a term to the filter.
@@ -298,7 +320,7 @@ func (f *FilterOp) GeoDistance(distance string, fields ...GeoField) *FilterOp {
 	f.GeoDistMap = make(map[string]interface{})
 	f.GeoDistMap["distance"] = distance
 	for _, val := range fields {
-		f.GeoDistMap[val.Field] = val.GeoLocation
+		f.GeoDistMap[val.Field] = val.point()
 	}
 
 	return f
@@ -314,12 +336,98 @@ func (f *FilterOp) GeoDistanceRange(from string, to string, fields ...GeoField)
 	f.GeoDistRangeMap["to"] = to
 
 	for _, val := range fields {
-		f.GeoDistRangeMap[val.Field] = val.GeoLocation
+		f.GeoDistRangeMap[val.Field] = val.point()
 	}
 
 	return f
 }
 
+// Nested will add a NESTED op to the filter, matching docs having nested
+// objects at path that match the query
+func (f *FilterOp) Nested(path string, query *QueryDsl) *FilterOp {
+	f.join = &JoinQuery{Path: path, QueryVal: query}
+	f.NestedProp = f.join
+	return f
+}
+
+// HasChild will add a HAS CHILD op to the filter, matching parent docs having
+// children of the type that match the query
+func (f *FilterOp) HasChild(_type string, query *QueryDsl) *FilterOp {
+	f.join = &JoinQuery{Type: _type, QueryVal: query}
+	f.HasChildProp = f.join
+	return f
+}
+
+// HasParent will add a HAS PARENT op to the filter, matching child docs whose
+// parent of the type matches the query
+func (f *FilterOp) HasParent(parentType string, query *QueryDsl) *FilterOp {
+	f.join = &JoinQuery{ParentType: parentType, QueryVal: query}
+	f.HasParentProp = f.join
+	return f
+}
+
+// ScoreMode sets the score_mode of the last Nested, HasChild or HasParent op,
+// only used when the filter is in a scoring context
+func (f *FilterOp) ScoreMode(mode string) *FilterOp {
+	if f.join != nil {
+		f.join.ScoreMode = mode
+	}
+	return f
+}
+
+// InnerHits returns the matching nested objects or children/parents of the
+// last Nested, HasChild or HasParent op on each Hit
+func (f *FilterOp) InnerHits(innerHits *InnerHitsDsl) *FilterOp {
+	if f.join != nil {
+		f.join.InnerHitsVal = innerHits
+	}
+	return f
+}
+
+// GeoBoundingBox will add a GEO BOUNDING BOX op to the filter, matching
+// locations of the field inside the box.
+func (f *FilterOp) GeoBoundingBox(field string, topLeft, bottomRight GeoPoint) *FilterOp {
+	f.GeoBoxMap = map[string]interface{}{
+		field: map[string]GeoPoint{
+			"top_left":     topLeft,
+			"bottom_right": bottomRight,
+		},
+	}
+	return f
+}
+
+// GeoPolygon will add a GEO POLYGON op to the filter, matching locations of
+// the field inside the polygon.
+// points: the points of the polygon, it is closed automatically
+func (f *FilterOp) GeoPolygon(field string, points ...GeoPoint) *FilterOp {
+	f.GeoPolygonMap = map[string]interface{}{
+		field: map[string][]GeoPoint{"points": points},
+	}
+	return f
+}
+
+// GeohashCell will add a GEOHASH CELL op to the filter, matching locations
+// of the field in the geohash cell of the location.
+// precision: the length of the geohash, or a distance such as "50m"
+// neighbors: whether to also match the cells surrounding the location
+func (f *FilterOp) GeohashCell(field string, location GeoPoint, precision interface{}, neighbors bool) *FilterOp {
+	f.GeohashCellMap = map[string]interface{}{field: location}
+	if precision != nil {
+		f.GeohashCellMap["precision"] = precision
+	}
+	if neighbors {
+		f.GeohashCellMap["neighbors"] = true
+	}
+	return f
+}
+
+// GeoShape will add a GEO SHAPE op to the filter, matching geo_shape fields
+// against an InlineShape or IndexedShape.
+func (f *FilterOp) GeoShape(field string, shape *GeoShapeFilter) *FilterOp {
+	f.GeoShapeMap = map[string]interface{}{field: shape}
+	return f
+}
+
 // NewGeoField is a helper function to create values for the GeoDistance filters
 func NewGeoField(field string, latitude float32, longitude float32) GeoField {
 	return GeoField{
@@ -327,6 +435,22 @@ func NewGeoField(field string, latitude float32, longitude float32) GeoField {
 		Field:       field}
 }
 
+// NewGeoPointField is NewGeoField for a location in any of the GeoPoint forms
+//
+//	Filter().GeoDistance("100km", NewGeoPointField("pin.location", GeoHash("drm3btev")))
+func NewGeoPointField(field string, point GeoPoint) GeoField {
+	gf := GeoField{Field: field, Point: point}
+	switch p := point.(type) {
+	case GeoLocation:
+		gf.GeoLocation = p
+	case GeoArray:
+		gf.GeoLocation = GeoLocation(p)
+	case GeoHash:
+		gf.GeoLocation, _ = p.Location()
+	}
+	return gf
+}
+
 // Terms adds a TERMS op to the filter.
 // field: the document field
 // executionMode Term execution mode, starts with TEM
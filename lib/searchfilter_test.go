@@ -200,6 +200,100 @@ func TestFilterDsl(t *testing.T) {
 		So(float64(32.3), ShouldEqual, actualLocation["lat"])
 		So(float64(23.4), ShouldEqual, actualLocation["lon"])
 	})
+
+	Convey("GeoBoundingBox filter", t, func() {
+		filter := Filter().GeoBoundingBox("pin.location",
+			GeoLocation{Latitude: 40.73, Longitude: -74.1},
+			GeoLocation{Latitude: 40.01, Longitude: -71.12}.AsArray())
+		actual, err := GetJson(filter)
+
+		actualValue := actual["geo_bounding_box"].(map[string]interface{})
+		actualBox := actualValue["pin.location"].(map[string]interface{})
+		actualTopLeft := actualBox["top_left"].(map[string]interface{})
+		actualBottomRight := actualBox["bottom_right"].([]interface{})
+		So(err, ShouldBeNil)
+		So(float64(40.73), ShouldEqual, actualTopLeft["lat"])
+		So(float64(-71.12), ShouldEqual, actualBottomRight[0])
+		So(float64(40.01), ShouldEqual, actualBottomRight[1])
+	})
+
+	Convey("GeoPolygon filter", t, func() {
+		filter := Filter().GeoPolygon("pin.location",
+			GeoLocation{Latitude: 40, Longitude: -70},
+			GeoLocation{Latitude: 30, Longitude: -80},
+			GeoLocation{Latitude: 20, Longitude: -90}.AsGeohash(5))
+		actual, err := GetJson(filter)
+
+		actualValue := actual["geo_polygon"].(map[string]interface{})
+		actualPoints := actualValue["pin.location"].(map[string]interface{})["points"].([]interface{})
+		So(err, ShouldBeNil)
+		So(3, ShouldEqual, len(actualPoints))
+		So("d581b", ShouldEqual, actualPoints[2])
+	})
+
+	Convey("GeohashCell filter", t, func() {
+		filter := Filter().GeohashCell("pin", GeoHash("u30d"), "50m", true)
+		actual, err := GetJson(filter)
+
+		actualValue := actual["geohash_cell"].(map[string]interface{})
+		So(err, ShouldBeNil)
+		So("u30d", ShouldEqual, actualValue["pin"])
+		So("50m", ShouldEqual, actualValue["precision"])
+		So(true, ShouldEqual, actualValue["neighbors"])
+	})
+
+	Convey("GeoShape filter", t, func() {
+		filter := Filter().GeoShape("location",
+			InlineShape("envelope", [][]float64{{13.0, 53.0}, {14.0, 52.0}}).Relation("within"))
+		actual, err := GetJson(filter)
+
+		actualValue := actual["geo_shape"].(map[string]interface{})["location"].(map[string]interface{})
+		actualShape := actualValue["shape"].(map[string]interface{})
+		So(err, ShouldBeNil)
+		So("envelope", ShouldEqual, actualShape["type"])
+		So(2, ShouldEqual, len(actualShape["coordinates"].([]interface{})))
+		So("within", ShouldEqual, actualValue["relation"])
+
+		filter = Filter().GeoShape("location", IndexedShape("shapes", "country", "DEU", "location"))
+		actual, err = GetJson(filter)
+
+		actualValue = actual["geo_shape"].(map[string]interface{})["location"].(map[string]interface{})
+		actualIndexed := actualValue["indexed_shape"].(map[string]interface{})
+		So(err, ShouldBeNil)
+		So(false, ShouldEqual, HasKey(actualValue, "shape"))
+		So("DEU", ShouldEqual, actualIndexed["id"])
+		So("country", ShouldEqual, actualIndexed["type"])
+		So("shapes", ShouldEqual, actualIndexed["index"])
+	})
+
+	Convey("Nested filter", t, func() {
+		filter := Filter().Nested("comments", Query().Term("comments.author", "kimchy")).
+			InnerHits(InnerHits().Size(1))
+		actual, err := GetJson(filter)
+
+		actualValue := actual["nested"].(map[string]interface{})
+		actualInner := actualValue["inner_hits"].(map[string]interface{})
+		So(err, ShouldBeNil)
+		So(1, ShouldEqual, len(actual))
+		So("comments", ShouldEqual, actualValue["path"])
+		So(true, ShouldEqual, HasKey(actualValue, "query"))
+		So(float64(1), ShouldEqual, actualInner["size"])
+	})
+
+	Convey("HasChild and HasParent filters", t, func() {
+		filter := Filter().HasChild("answer", Query().Term("accepted", true)).ScoreMode("max").
+			HasParent("question", Query().All())
+		actual, err := GetJson(filter)
+
+		actualChild := actual["has_child"].(map[string]interface{})
+		actualParent := actual["has_parent"].(map[string]interface{})
+		So(err, ShouldBeNil)
+		So(2, ShouldEqual, len(actual))
+		So("answer", ShouldEqual, actualChild["type"])
+		So("max", ShouldEqual, actualChild["score_mode"])
+		So("question", ShouldEqual, actualParent["parent_type"])
+		So(false, ShouldEqual, HasKey(actualParent, "score_mode"))
+	})
 }
 
 func TestFilters(t *testing.T) {
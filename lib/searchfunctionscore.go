@@ -0,0 +1,238 @@
+// Copyright 2013 Matthew Baird
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//     http://www.apache.org/licenses/LICENSE-2.0
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package elastigo
+
+// FunctionScore creates a blank function_score query, set it on a query with
+// QueryDsl.FunctionScoreQuery
+// http://www.elastic.co/guide/en/elasticsearch/reference/1.x/query-dsl-function-score-query.html
+func FunctionScore() *FunctionScoreQuery {
+	return &FunctionScoreQuery{}
+}
+
+type FunctionScoreQuery struct {
+	QueryVal     *QueryDsl        `json:"query,omitempty"`
+	FunctionsVal []*ScoreFunction `json:"functions,omitempty"`
+	ScoreModeVal string           `json:"score_mode,omitempty"`
+	BoostModeVal string           `json:"boost_mode,omitempty"`
+	MaxBoostVal  float64          `json:"max_boost,omitempty"`
+	MinScoreVal  float64          `json:"min_score,omitempty"`
+	BoostVal     float64          `json:"boost,omitempty"`
+}
+
+// Query sets the query whose docs are scored, defaults to match_all
+func (f *FunctionScoreQuery) Query(q *QueryDsl) *FunctionScoreQuery {
+	f.QueryVal = q
+	return f
+}
+
+// Add adds score functions
+func (f *FunctionScoreQuery) Add(functions ...*ScoreFunction) *FunctionScoreQuery {
+	f.FunctionsVal = append(f.FunctionsVal, functions...)
+	return f
+}
+
+// ScoreMode sets how the function scores are combined, multiply (default),
+// sum, avg, first, max or min
+func (f *FunctionScoreQuery) ScoreMode(mode string) *FunctionScoreQuery {
+	f.ScoreModeVal = mode
+	return f
+}
+
+// BoostMode sets how the combined function score is combined with the query
+// score, multiply (default), replace, sum, avg, max or min
+func (f *FunctionScoreQuery) BoostMode(mode string) *FunctionScoreQuery {
+	f.BoostModeVal = mode
+	return f
+}
+
+// MaxBoost caps the combined function score
+func (f *FunctionScoreQuery) MaxBoost(max float64) *FunctionScoreQuery {
+	f.MaxBoostVal = max
+	return f
+}
+
+// MinScore excludes docs scoring below min
+func (f *FunctionScoreQuery) MinScore(min float64) *FunctionScoreQuery {
+	f.MinScoreVal = min
+	return f
+}
+
+func (f *FunctionScoreQuery) Boost(boost float64) *FunctionScoreQuery {
+	f.BoostVal = boost
+	return f
+}
+
+// ScoreFunction is one function of a function_score query, create it with
+// WeightFunction, RandomScoreFunction, FieldValueFactorFunction,
+// ScriptScoreFunction or the Gauss/Linear/Exp decay functions.  Setters that
+// don't apply to the kind of function are ignored.
+type ScoreFunction struct {
+	FilterVal           *FilterOp              `json:"filter,omitempty"`
+	WeightVal           float64                `json:"weight,omitempty"`
+	RandomScoreVal      *RandomScore           `json:"random_score,omitempty"`
+	FieldValueFactorVal *FieldValueFactor      `json:"field_value_factor,omitempty"`
+	ScriptScoreVal      *ScriptScore           `json:"script_score,omitempty"`
+	GaussVal            map[string]interface{} `json:"gauss,omitempty"`
+	LinearVal           map[string]interface{} `json:"linear,omitempty"`
+	ExpVal              map[string]interface{} `json:"exp,omitempty"`
+
+	decay *DecayFunction
+	// gauss, linear or exp, whichever is set
+	decayMap map[string]interface{}
+}
+
+type RandomScore struct {
+	Seed interface{} `json:"seed,omitempty"`
+}
+
+type FieldValueFactor struct {
+	Field    string      `json:"field"`
+	Factor   float64     `json:"factor,omitempty"`
+	Modifier string      `json:"modifier,omitempty"`
+	Missing  interface{} `json:"missing,omitempty"`
+}
+
+type ScriptScore struct {
+	Script string                 `json:"script"`
+	Lang   string                 `json:"lang,omitempty"`
+	Params map[string]interface{} `json:"params,omitempty"`
+}
+
+// DecayFunction scores docs by how far the field is from origin, the score is
+// decay at scale (plus offset) from the origin
+type DecayFunction struct {
+	Origin interface{} `json:"origin,omitempty"`
+	Scale  interface{} `json:"scale"`
+	Offset interface{} `json:"offset,omitempty"`
+	Decay  float64     `json:"decay,omitempty"`
+}
+
+// WeightFunction multiplies the score by weight, use Filter to only apply it
+// to some docs
+func WeightFunction(weight float64) *ScoreFunction {
+	return &ScoreFunction{WeightVal: weight}
+}
+
+// RandomScoreFunction scores docs randomly, the same seed gives the same order
+func RandomScoreFunction(seed interface{}) *ScoreFunction {
+	return &ScoreFunction{RandomScoreVal: &RandomScore{Seed: seed}}
+}
+
+// FieldValueFactorFunction scores using the value of a numeric field, see
+// Factor, Modifier and Missing
+func FieldValueFactorFunction(field string) *ScoreFunction {
+	return &ScoreFunction{FieldValueFactorVal: &FieldValueFactor{Field: field}}
+}
+
+// ScriptScoreFunction scores with a script, params may be nil
+func ScriptScoreFunction(script string, params map[string]interface{}) *ScoreFunction {
+	return &ScoreFunction{ScriptScoreVal: &ScriptScore{Script: script, Params: params}}
+}
+
+// GaussFunction is a decay function with a normal curve
+//
+//	GaussFunction("location", "11, 12", "2km").Offset("0km").Decay(0.33)
+func GaussFunction(field string, origin interface{}, scale interface{}) *ScoreFunction {
+	f := &ScoreFunction{}
+	f.GaussVal = f.setDecay(field, origin, scale)
+	return f
+}
+
+// LinearFunction is a decay function that reaches zero at twice the scale
+func LinearFunction(field string, origin interface{}, scale interface{}) *ScoreFunction {
+	f := &ScoreFunction{}
+	f.LinearVal = f.setDecay(field, origin, scale)
+	return f
+}
+
+// ExpFunction is an exponential decay function
+func ExpFunction(field string, origin interface{}, scale interface{}) *ScoreFunction {
+	f := &ScoreFunction{}
+	f.ExpVal = f.setDecay(field, origin, scale)
+	return f
+}
+
+func (f *ScoreFunction) setDecay(field string, origin, scale interface{}) map[string]interface{} {
+	f.decay = &DecayFunction{Origin: origin, Scale: scale}
+	f.decayMap = map[string]interface{}{field: f.decay}
+	return f.decayMap
+}
+
+// Filter only applies the function to docs matching the filter
+func (f *ScoreFunction) Filter(filter *FilterOp) *ScoreFunction {
+	f.FilterVal = filter
+	return f
+}
+
+// Weight multiplies the result of the function
+func (f *ScoreFunction) Weight(weight float64) *ScoreFunction {
+	f.WeightVal = weight
+	return f
+}
+
+// Factor multiplies the field value of a field_value_factor function
+func (f *ScoreFunction) Factor(factor float64) *ScoreFunction {
+	if f.FieldValueFactorVal != nil {
+		f.FieldValueFactorVal.Factor = factor
+	}
+	return f
+}
+
+// Modifier applies to the field value of a field_value_factor function, none,
+// log, log1p, log2p, ln, ln1p, ln2p, square, sqrt or reciprocal
+func (f *ScoreFunction) Modifier(modifier string) *ScoreFunction {
+	if f.FieldValueFactorVal != nil {
+		f.FieldValueFactorVal.Modifier = modifier
+	}
+	return f
+}
+
+// Missing is the value of a field_value_factor function for docs without the field
+func (f *ScoreFunction) Missing(missing interface{}) *ScoreFunction {
+	if f.FieldValueFactorVal != nil {
+		f.FieldValueFactorVal.Missing = missing
+	}
+	return f
+}
+
+// Lang sets the language of a script_score function
+func (f *ScoreFunction) Lang(lang string) *ScoreFunction {
+	if f.ScriptScoreVal != nil {
+		f.ScriptScoreVal.Lang = lang
+	}
+	return f
+}
+
+// Offset sets the distance from origin before a decay function starts to decay
+func (f *ScoreFunction) Offset(offset interface{}) *ScoreFunction {
+	if f.decay != nil {
+		f.decay.Offset = offset
+	}
+	return f
+}
+
+// Decay sets the score of a decay function at scale from origin, default 0.5
+func (f *ScoreFunction) Decay(decay float64) *ScoreFunction {
+	if f.decay != nil {
+		f.decay.Decay = decay
+	}
+	return f
+}
+
+// MultiValueMode sets which value of a multi valued field a decay function
+// uses, min (default), max, avg or sum
+func (f *ScoreFunction) MultiValueMode(mode string) *ScoreFunction {
+	if f.decayMap != nil {
+		f.decayMap["multi_value_mode"] = mode
+	}
+	return f
+}
@@ -0,0 +1,219 @@
+// Copyright 2013 Matthew Baird
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//     http://www.apache.org/licenses/LICENSE-2.0
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package elastigo
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+const geohashBase32 = "0123456789bcdefghjkmnpqrstuvwxyz"
+
+// GeohashEncode returns the geohash of the point with precision characters,
+// between 1 and 12
+func GeohashEncode(lat, lon float64, precision int) string {
+	if precision < 1 {
+		precision = 1
+	} else if precision > 12 {
+		precision = 12
+	}
+	latRange := [2]float64{-90, 90}
+	lonRange := [2]float64{-180, 180}
+	hash := make([]byte, 0, precision)
+	even := true
+	bit, ch := 0, 0
+	for len(hash) < precision {
+		rng, val := &latRange, lat
+		if even {
+			rng, val = &lonRange, lon
+		}
+		mid := (rng[0] + rng[1]) / 2
+		ch <<= 1
+		if val >= mid {
+			ch |= 1
+			rng[0] = mid
+		} else {
+			rng[1] = mid
+		}
+		even = !even
+		if bit++; bit == 5 {
+			hash = append(hash, geohashBase32[ch])
+			bit, ch = 0, 0
+		}
+	}
+	return string(hash)
+}
+
+// GeohashDecode returns the center of the geohash cell
+func GeohashDecode(hash string) (lat, lon float64, err error) {
+	if len(hash) == 0 {
+		return 0, 0, fmt.Errorf("empty geohash")
+	}
+	latRange := [2]float64{-90, 90}
+	lonRange := [2]float64{-180, 180}
+	even := true
+	for _, c := range strings.ToLower(hash) {
+		ch := strings.IndexRune(geohashBase32, c)
+		if ch < 0 {
+			return 0, 0, fmt.Errorf("invalid geohash %q", hash)
+		}
+		for mask := 16; mask > 0; mask >>= 1 {
+			rng := &latRange
+			if even {
+				rng = &lonRange
+			}
+			mid := (rng[0] + rng[1]) / 2
+			if ch&mask != 0 {
+				rng[0] = mid
+			} else {
+				rng[1] = mid
+			}
+			even = !even
+		}
+	}
+	return (latRange[0] + latRange[1]) / 2, (lonRange[0] + lonRange[1]) / 2, nil
+}
+
+// GeoPoint is a location in one of the forms elasticsearch accepts, a
+// GeoLocation, sent as {"lat": 1, "lon": 2}, a GeoArray or a GeoHash
+type GeoPoint interface {
+	geoPoint()
+}
+
+func (g GeoLocation) geoPoint() {}
+
+// GeoHash is a location sent as its geohash
+type GeoHash string
+
+func (h GeoHash) geoPoint() {}
+
+// Location is the center of the geohash cell
+func (h GeoHash) Location() (GeoLocation, error) {
+	lat, lon, err := GeohashDecode(string(h))
+	return GeoLocation{Latitude: float32(lat), Longitude: float32(lon)}, err
+}
+
+// GeoArray is a location sent in the GeoJSON [lon, lat] form
+type GeoArray GeoLocation
+
+func (a GeoArray) geoPoint() {}
+
+func (a GeoArray) MarshalJSON() ([]byte, error) {
+	return json.Marshal([]float32{a.Longitude, a.Latitude})
+}
+
+// AsGeohash returns the location as a geohash of precision characters
+func (g GeoLocation) AsGeohash(precision int) GeoHash {
+	return GeoHash(GeohashEncode(float64(g.Latitude), float64(g.Longitude), precision))
+}
+
+// AsArray returns the location in the GeoJSON [lon, lat] form
+func (g GeoLocation) AsArray() GeoArray {
+	return GeoArray(g)
+}
+
+// ParseGeoLocation parses a location in any of the forms elasticsearch
+// accepts, {"lat": 1, "lon": 2}, "lat,lon", a geohash and [lon, lat]
+func ParseGeoLocation(data []byte) (GeoLocation, error) {
+	var g GeoLocation
+	switch {
+	case len(data) > 0 && data[0] == '[':
+		var coords []float32
+		if err := json.Unmarshal(data, &coords); err != nil {
+			return g, err
+		}
+		if len(coords) < 2 {
+			return g, fmt.Errorf("geo point array needs [lon, lat] but got %s", data)
+		}
+		g.Longitude, g.Latitude = coords[0], coords[1]
+	case len(data) > 0 && data[0] == '"':
+		var s string
+		if err := json.Unmarshal(data, &s); err != nil {
+			return g, err
+		}
+		if parts := strings.Split(s, ","); len(parts) == 2 {
+			lat, err := strconv.ParseFloat(strings.TrimSpace(parts[0]), 32)
+			if err != nil {
+				return g, err
+			}
+			lon, err := strconv.ParseFloat(strings.TrimSpace(parts[1]), 32)
+			if err != nil {
+				return g, err
+			}
+			g.Latitude, g.Longitude = float32(lat), float32(lon)
+			return g, nil
+		}
+		return GeoHash(s).Location()
+	default:
+		if err := json.Unmarshal(data, &g); err != nil {
+			return g, err
+		}
+	}
+	return g, nil
+}
+
+// GeoShapeFilter holds the shape of a geo_shape filter, create it with
+// InlineShape or IndexedShape
+// http://www.elastic.co/guide/en/elasticsearch/reference/1.x/query-dsl-geo-shape-filter.html
+type GeoShapeFilter struct {
+	Shape        *GeoJSON            `json:"shape,omitempty"`
+	IndexedShape *IndexedShapeLookup `json:"indexed_shape,omitempty"`
+	RelationVal  string              `json:"relation,omitempty"`
+}
+
+// GeoJSON is a shape in GeoJSON format, coordinates are [lon, lat] pairs
+type GeoJSON struct {
+	Type        string      `json:"type"`
+	Coordinates interface{} `json:"coordinates,omitempty"`
+	// only for circles, "100m"
+	Radius string `json:"radius,omitempty"`
+	// only for geometrycollection
+	Geometries []*GeoJSON `json:"geometries,omitempty"`
+}
+
+// IndexedShapeLookup points to a shape indexed in another document
+type IndexedShapeLookup struct {
+	Id    string `json:"id"`
+	Type  string `json:"type"`
+	Index string `json:"index,omitempty"`
+	Path  string `json:"path,omitempty"`
+}
+
+// InlineShape creates a geo_shape filter shape from GeoJSON
+//
+//	InlineShape("envelope", [][]float64{{13.0, 53.0}, {14.0, 52.0}})
+func InlineShape(shapeType string, coordinates interface{}) *GeoShapeFilter {
+	return &GeoShapeFilter{Shape: &GeoJSON{Type: shapeType, Coordinates: coordinates}}
+}
+
+// IndexedShape creates a geo_shape filter shape using the shape in the path
+// field (default shape) of another document, index defaults to shapes
+func IndexedShape(index, _type, id, path string) *GeoShapeFilter {
+	return &GeoShapeFilter{IndexedShape: &IndexedShapeLookup{Id: id, Type: _type, Index: index, Path: path}}
+}
+
+// Radius sets the radius of an inline circle shape
+func (s *GeoShapeFilter) Radius(radius string) *GeoShapeFilter {
+	if s.Shape != nil {
+		s.Shape.Radius = radius
+	}
+	return s
+}
+
+// Relation sets how the docs shapes relate to this one, intersects (default),
+// disjoint or within
+func (s *GeoShapeFilter) Relation(relation string) *GeoShapeFilter {
+	s.RelationVal = relation
+	return s
+}
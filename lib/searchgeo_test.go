@@ -0,0 +1,92 @@
+// Copyright 2013 Matthew Baird
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//     http://www.apache.org/licenses/LICENSE-2.0
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package elastigo
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/bmizerany/assert"
+)
+
+func TestGeohash(t *testing.T) {
+	assert.Equal(t, "u4pruydqqvj", GeohashEncode(57.64911, 10.40744, 11))
+	assert.Equal(t, "u4pru", GeohashEncode(57.64911, 10.40744, 5))
+
+	lat, lon, err := GeohashDecode("u4pruydqqvj")
+	assert.Equal(t, nil, err)
+	assert.T(t, lat > 57.6491 && lat < 57.6492, "lat", lat)
+	assert.T(t, lon > 10.4074 && lon < 10.4075, "lon", lon)
+
+	_, _, err = GeohashDecode("u4pa")
+	assert.NotEqual(t, nil, err)
+}
+
+func TestGeoLocationForms(t *testing.T) {
+	var raw []json.RawMessage
+	err := json.Unmarshal([]byte(`[
+		{"lat": 41.12, "lon": -71.34},
+		"41.12,-71.34",
+		[-71.34, 41.12],
+		"drm3btev3e86"
+	]`), &raw)
+	assert.Equal(t, nil, err)
+	for _, data := range raw {
+		loc, err := ParseGeoLocation(data)
+		assert.Equal(t, nil, err)
+		assert.T(t, loc.Latitude > 41.11 && loc.Latitude < 41.13, "lat", loc.Latitude)
+		assert.T(t, loc.Longitude > -71.35 && loc.Longitude < -71.33, "lon", loc.Longitude)
+	}
+	_, err = ParseGeoLocation([]byte(`[1]`))
+	assert.NotEqual(t, nil, err)
+
+	loc := GeoLocation{41.12, -71.34}
+	out, err := json.Marshal([]GeoPoint{loc, loc.AsArray(), loc.AsGeohash(8), GeoHash("drm3")})
+	assert.Equal(t, nil, err)
+	assert.Equal(t, `[{"lat":41.12,"lon":-71.34},[-71.34,41.12],"drm3btev","drm3"]`, string(out))
+}
+
+func TestGeoFieldJson(t *testing.T) {
+	// the fields of types embedding GeoLocation are all sent
+	out, err := json.Marshal(NewGeoField("pin.location", 32.3, 23.4))
+	assert.Equal(t, nil, err)
+	assert.Equal(t, `{"lat":32.3,"lon":23.4,"Field":"pin.location"}`, string(out))
+
+	var field GeoField
+	err = json.Unmarshal([]byte(`{"lat":32.3,"lon":23.4,"Field":"pin.location"}`), &field)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, "pin.location", field.Field)
+	assert.Equal(t, float32(32.3), field.Latitude)
+}
+
+func TestGeoDistanceFilterPoints(t *testing.T) {
+	location := GeoLocation{Latitude: 41.12, Longitude: -71.34}
+	out, err := json.Marshal(Filter().GeoDistance("100km",
+		NewGeoPointField("pin.location", location.AsGeohash(8)),
+		NewGeoPointField("pin.home", location.AsArray()),
+		NewGeoField("pin.work", 41.12, -71.34),
+	))
+	assert.Equal(t, nil, err)
+	assertJsonMatch(t, out, []byte(`{"geo_distance": {
+		"distance": "100km",
+		"pin.location": "drm3btev",
+		"pin.home": [-71.34, 41.12],
+		"pin.work": {"lat": 41.12, "lon": -71.34}
+	}}`))
+
+	out, err = json.Marshal(Filter().GeoDistanceRange("100km", "200km", NewGeoPointField("pin.location", GeoHash("drm3"))))
+	assert.Equal(t, nil, err)
+	assertJsonMatch(t, out, []byte(`{"geo_distance_range": {"from": "100km", "to": "200km", "pin.location": "drm3"}}`))
+
+	field := NewGeoPointField("pin.location", location.AsArray())
+	assert.Equal(t, float32(41.12), field.Latitude)
+}
@@ -27,6 +27,10 @@ type HighlightEmbed struct {
 	MatchedFieldsVal   []string  `json:"matched_fields,omitempty"`
 	OrderVal           string    `json:"order,omitempty"`
 	TypeVal            string    `json:"type,omitempty"`
+
+	// nil leaves the default, true
+	RequireFieldMatchVal *bool `json:"require_field_match,omitempty"`
+	NoMatchSizeVal       int   `json:"no_match_size,omitempty"`
 }
 
 // Custom marshalling
@@ -136,3 +140,24 @@ func (he *HighlightEmbed) Type(highlightType string) *HighlightEmbed {
 	he.TypeVal = highlightType
 	return he
 }
+
+// Query sets the highlight_query, highlighting the matches of a query other
+// than the search query, to include rescore queries for example
+func (he *HighlightEmbed) Query(query *QueryDsl) *HighlightEmbed {
+	he.HighlightQuery = query
+	return he
+}
+
+// RequireFieldMatch set to false highlights matches of the query in every
+// field, not only in the fields the query searched
+func (he *HighlightEmbed) RequireFieldMatch(require bool) *HighlightEmbed {
+	he.RequireFieldMatchVal = &require
+	return he
+}
+
+// NoMatchSize returns this many characters from the start of the field as a
+// fragment when nothing in it matched
+func (he *HighlightEmbed) NoMatchSize(size int) *HighlightEmbed {
+	he.NoMatchSizeVal = size
+	return he
+}
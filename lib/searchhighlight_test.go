@@ -1,6 +1,7 @@
 package elastigo
 
 import (
+	"encoding/json"
 	"github.com/bmizerany/assert"
 	"testing"
 )
@@ -65,3 +66,56 @@ func TestEmbedAndFieldDsl(t *testing.T) {
 	assert.Equal(t, "</div>", actual["post_tags"].([]interface{})[0])
 	assert.Equal(t, "something", actualField["type"])
 }
+
+func TestHighlightQueryOptions(t *testing.T) {
+	highlight := NewHighlight().AddField("content", NewHighlightOpts().
+		Query(Query().Match("content", "foo bar")).
+		RequireFieldMatch(false).
+		NoMatchSize(150))
+
+	result, err := GetJson(highlight)
+	actual := result["fields"].(map[string]interface{})["content"].(map[string]interface{})
+
+	assert.Equal(t, nil, err)
+	assert.Equal(t, false, actual["require_field_match"])
+	assert.Equal(t, float64(150), actual["no_match_size"])
+	assert.T(t, HasKey(actual["highlight_query"].(map[string]interface{}), "match"))
+
+	result, err = GetJson(NewHighlight().AddField("title", nil))
+	actual = result["fields"].(map[string]interface{})["title"].(map[string]interface{})
+
+	assert.Equal(t, nil, err)
+	assert.Equal(t, 0, len(actual))
+}
+
+func TestHitHighlight(t *testing.T) {
+	var hit Hit
+	err := json.Unmarshal([]byte(`{
+		"_id": "1",
+		"_source": {
+			"title": "The quick brown fox",
+			"user": { "bio": "likes foxes and dogs" },
+			"tags": [ "fox", "dog" ],
+			"body": "untouched"
+		},
+		"highlight": {
+			"title.english": [ "The quick brown <em>fox</em>" ],
+			"user.bio": [ "likes <em>foxes</em>", "and <em>dogs</em>" ],
+			"tags": [ "<em>fox</em>" ],
+			"missing": [ "<em>gone</em>" ]
+		}
+	}`), &hit)
+	assert.Equal(t, nil, err)
+
+	assert.Equal(t, []string{"likes <em>foxes</em>", "and <em>dogs</em>"}, hit.Highlight["user.bio"])
+	assert.Equal(t, "likes <em>foxes</em> ... and <em>dogs</em>", hit.Highlight.Snippet("user.bio"))
+	assert.Equal(t, "", hit.Highlight.Snippet("body"))
+
+	source, err := hit.HighlightedSource()
+	assert.Equal(t, nil, err)
+	assert.Equal(t, "The quick brown <em>fox</em>", source["title"])
+	assert.Equal(t, "likes <em>foxes</em> ... and <em>dogs</em>", source["user"].(map[string]interface{})["bio"])
+	assert.Equal(t, []interface{}{"fox", "dog"}, source["tags"])
+	assert.Equal(t, "untouched", source["body"])
+	assert.Equal(t, false, HasKey(source, "missing"))
+}
@@ -0,0 +1,111 @@
+// Copyright 2013 Matthew Baird
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//     http://www.apache.org/licenses/LICENSE-2.0
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package elastigo
+
+// JoinQuery holds a nested, has_child or has_parent query or filter, set it
+// with Nested, HasChild or HasParent on a QueryDsl or FilterOp and use the
+// ScoreMode and InnerHits functions following it for the options.
+// http://www.elastic.co/guide/en/elasticsearch/reference/1.x/joining-queries.html
+type JoinQuery struct {
+	// the nested object path, for nested
+	Path string `json:"path,omitempty"`
+	// the child type, for has_child
+	Type string `json:"type,omitempty"`
+	// the parent type, for has_parent
+	ParentType   string        `json:"parent_type,omitempty"`
+	QueryVal     *QueryDsl     `json:"query,omitempty"`
+	ScoreMode    string        `json:"score_mode,omitempty"`
+	MinChildren  int           `json:"min_children,omitempty"`
+	MaxChildren  int           `json:"max_children,omitempty"`
+	InnerHitsVal *InnerHitsDsl `json:"inner_hits,omitempty"`
+	Boost        float64       `json:"boost,omitempty"`
+}
+
+func (j *JoinQuery) setBoost(boost float64) {
+	j.Boost = boost
+}
+
+// ParentIdQuery matches the children of one parent document
+type ParentIdQuery struct {
+	Type string `json:"type"`
+	Id   string `json:"id"`
+}
+
+// InnerHits creates a blank inner_hits definition, returning the nested
+// objects or child/parent docs that caused each hit to match
+//
+//	Query().Nested("comments", Query().Match("comments.text", "great")).
+//		InnerHits(InnerHits().Size(3).Sort(Sort("comments.date").Desc()))
+func InnerHits() *InnerHitsDsl {
+	return &InnerHitsDsl{}
+}
+
+type InnerHitsDsl struct {
+	NameVal      string        `json:"name,omitempty"`
+	FromVal      int           `json:"from,omitempty"`
+	SizeVal      int           `json:"size,omitempty"`
+	SortVal      []*SortDsl    `json:"sort,omitempty"`
+	SourceVal    interface{}   `json:"_source,omitempty"`
+	HighlightVal *HighlightDsl `json:"highlight,omitempty"`
+}
+
+// Name sets the key the inner hits are returned under on the Hit, defaults
+// to the path or type of the query
+func (i *InnerHitsDsl) Name(name string) *InnerHitsDsl {
+	i.NameVal = name
+	return i
+}
+
+func (i *InnerHitsDsl) From(from int) *InnerHitsDsl {
+	i.FromVal = from
+	return i
+}
+
+// Size sets the max number of inner hits returned per hit, defaults to 3
+func (i *InnerHitsDsl) Size(size int) *InnerHitsDsl {
+	i.SizeVal = size
+	return i
+}
+
+func (i *InnerHitsDsl) Sort(sort ...*SortDsl) *InnerHitsDsl {
+	i.SortVal = append(i.SortVal, sort...)
+	return i
+}
+
+// Source limits the returned _source of the inner hits to the fields, with
+// no fields the _source isn't returned at all
+func (i *InnerHitsDsl) Source(fields ...string) *InnerHitsDsl {
+	if len(fields) == 0 {
+		i.SourceVal = false
+	} else {
+		i.SourceVal = fields
+	}
+	return i
+}
+
+func (i *InnerHitsDsl) Highlight(highlight *HighlightDsl) *InnerHitsDsl {
+	i.HighlightVal = highlight
+	return i
+}
+
+// InnerHitsResult is one named set of inner hits of a Hit
+type InnerHitsResult struct {
+	Hits Hits `json:"hits"`
+}
+
+// NestedIdentity identifies the nested object an inner hit is, Nested is set
+// for objects nested more than one level deep
+type NestedIdentity struct {
+	Field  string          `json:"field"`
+	Offset int             `json:"offset"`
+	Nested *NestedIdentity `json:"_nested,omitempty"`
+}
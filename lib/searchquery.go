@@ -57,6 +57,17 @@ some ways to serialize
 type QueryDsl struct {
 	QueryEmbed
 	FilterVal *FilterOp `json:"filter,omitempty"`
+	// the last query set, the options following it apply to it if it is a
+	// *MatchQuery, *FuzzyQuery, *RegexpQuery, *RangeQuery or *JoinQuery
+	last interface{}
+	// sets the boost of the last query set
+	boost func(float64)
+}
+
+// Make query the one the options and Boost apply to, nil if it has none
+func (q *QueryDsl) setLast(query interface{}, boost func(float64)) {
+	q.last = query
+	q.boost = boost
 }
 
 // The core Query Syntax can be embedded as a child of a variety of different parents
@@ -66,6 +77,38 @@ type QueryEmbed struct {
 	Qs            *QueryString           `json:"query_string,omitempty"`
 	MultiMatch    *MultiMatch            `json:"multi_match,omitempty"`
 	FunctionScore map[string]interface{} `json:"function_score,omitempty"`
+	BoolVal       *BoolQuery             `json:"bool,omitempty"`
+	// term queries set by Term that aren't a plain string, numbers, bools
+	// or boosted terms, they are sent along with Terms
+	TermVal map[string]interface{} `json:"-"`
+	// the function_score set by FunctionScoreQuery, it is sent in place of
+	// FunctionScore
+	FunctionScoreVal *FunctionScoreQuery `json:"-"`
+
+	MatchVal             map[string]*MatchQuery `json:"match,omitempty"`
+	MatchPhraseVal       map[string]*MatchQuery `json:"match_phrase,omitempty"`
+	MatchPhrasePrefixVal map[string]*MatchQuery `json:"match_phrase_prefix,omitempty"`
+
+	PrefixVal   map[string]*TermQuery   `json:"prefix,omitempty"`
+	WildcardVal map[string]*TermQuery   `json:"wildcard,omitempty"`
+	RegexpVal   map[string]*RegexpQuery `json:"regexp,omitempty"`
+	FuzzyVal    map[string]*FuzzyQuery  `json:"fuzzy,omitempty"`
+	RangeVal    map[string]*RangeQuery  `json:"range,omitempty"`
+	TermsVal    map[string]interface{}  `json:"terms,omitempty"`
+	ExistsVal   *propertyPathMarker     `json:"exists,omitempty"`
+	IdsVal      *IdsQuery               `json:"ids,omitempty"`
+
+	NestedVal    *JoinQuery     `json:"nested,omitempty"`
+	HasChildVal  *JoinQuery     `json:"has_child,omitempty"`
+	HasParentVal *JoinQuery     `json:"has_parent,omitempty"`
+	ParentIdVal  *ParentIdQuery `json:"parent_id,omitempty"`
+
+	DisMaxVal        *DisMaxQuery        `json:"dis_max,omitempty"`
+	ConstantScoreVal *ConstantScoreQuery `json:"constant_score,omitempty"`
+	BoostingVal      *BoostingQuery      `json:"boosting,omitempty"`
+	IndicesVal       *IndicesQuery       `json:"indices,omitempty"`
+
+	SimpleQsVal *SimpleQueryStringQuery `json:"simple_query_string,omitempty"`
 	//Exist    string            `json:"_exists_,omitempty"`
 }
 
@@ -73,28 +116,57 @@ type QueryEmbed struct {
 // json format, not always the same parent/children
 func (qd *QueryDsl) MarshalJSON() ([]byte, error) {
 	q := qd.QueryEmbed
-	hasQuery := false
-	if q.Qs != nil || len(q.Terms) > 0 || q.MatchAll != nil || q.MultiMatch != nil {
-		hasQuery = true
+	if q.FunctionScoreVal != nil {
+		fsB, err := json.Marshal(q.FunctionScoreVal)
+		if err != nil {
+			return fsB, err
+		}
+		var fs map[string]json.RawMessage
+		if err := json.Unmarshal(fsB, &fs); err != nil {
+			return nil, err
+		}
+		q.FunctionScore = make(map[string]interface{}, len(fs))
+		for key, val := range fs {
+			q.FunctionScore[key] = val
+		}
 	}
+	var queryB []byte
+	var err error
+	if len(q.TermVal) > 0 {
+		terms := make(map[string]interface{}, len(q.Terms)+len(q.TermVal))
+		for name, val := range q.Terms {
+			terms[name] = val
+		}
+		for name, val := range q.TermVal {
+			terms[name] = val
+		}
+		// the outer term field takes precedence over the embedded Terms
+		queryB, err = json.Marshal(struct {
+			QueryEmbed
+			Term map[string]interface{} `json:"term"`
+		}{q, terms})
+	} else {
+		queryB, err = json.Marshal(q)
+	}
+	if err != nil {
+		return queryB, err
+	}
+	hasQuery := string(queryB) != "{}"
 	// If a query has a
 	if qd.FilterVal != nil && hasQuery {
-		queryB, err := json.Marshal(q)
-		if err != nil {
-			return queryB, err
-		}
 		filterB, err := json.Marshal(qd.FilterVal)
 		if err != nil {
 			return filterB, err
 		}
 		return []byte(fmt.Sprintf(`{"filtered":{"query":%s,"filter":%s}}`, queryB, filterB)), nil
 	}
-	return json.Marshal(q)
+	return queryB, nil
 }
 
 // get all
 func (q *QueryDsl) All() *QueryDsl {
 	q.MatchAll = &MatchAll{""}
+	q.setLast(nil, nil)
 	return q
 }
 
@@ -109,23 +181,307 @@ func (q *QueryDsl) Range(fop *FilterOp) *QueryDsl {
 	return q
 }
 
-// Add a term search for a specific field
+// Add a term search for a specific field, the value is not analyzed and
+// may be a string, number or bool
 //    Term("user","kimchy")
-func (q *QueryDsl) Term(name, value string) *QueryDsl {
-	if len(q.Terms) == 0 {
-		q.Terms = make(map[string]string)
+func (q *QueryDsl) Term(name string, value interface{}) *QueryDsl {
+	if str, ok := value.(string); ok {
+		if len(q.Terms) == 0 {
+			q.Terms = make(map[string]string)
+		}
+		q.Terms[name] = str
+		delete(q.TermVal, name)
+	} else {
+		q.setTermVal(name, value)
 	}
-	q.Terms[name] = value
+	q.setLast(nil, func(boost float64) {
+		q.setTermVal(name, map[string]interface{}{"value": value, "boost": boost})
+	})
+	return q
+}
+
+func (q *QueryDsl) setTermVal(name string, value interface{}) {
+	if len(q.TermVal) == 0 {
+		q.TermVal = make(map[string]interface{})
+	}
+	q.TermVal[name] = value
+	delete(q.Terms, name)
+}
+
+// TermsQuery adds a terms query, matching docs where the field has any of the
+// values
+//
+//	Query().TermsQuery("user", "kimchy", "elasticsearch")
+func (q *QueryDsl) TermsQuery(field string, values ...interface{}) *QueryDsl {
+	q.TermsVal = map[string]interface{}{field: values}
+	q.setLast(nil, func(boost float64) {
+		q.TermsVal["boost"] = boost
+	})
+	return q
+}
+
+// Prefix adds a prefix query, for terms of the (not analyzed) field starting
+// with prefix
+func (q *QueryDsl) Prefix(field, prefix string) *QueryDsl {
+	tq := &TermQuery{Value: prefix}
+	q.PrefixVal = map[string]*TermQuery{field: tq}
+	q.setLast(nil, tq.setBoost)
+	return q
+}
+
+// Wildcard adds a wildcard query, * matches any characters and ? a single one
+//
+//	Query().Wildcard("user", "ki*y")
+func (q *QueryDsl) Wildcard(field, pattern string) *QueryDsl {
+	tq := &TermQuery{Value: pattern}
+	q.WildcardVal = map[string]*TermQuery{field: tq}
+	q.setLast(nil, tq.setBoost)
+	return q
+}
+
+// Regexp adds a regexp query, see RegexpFlags and MaxDeterminizedStates
+//
+//	Query().Regexp("name.first", "s.*y").RegexpFlags("INTERSECTION|COMPLEMENT")
+func (q *QueryDsl) Regexp(field, regexp string) *QueryDsl {
+	rq := &RegexpQuery{Value: regexp}
+	q.RegexpVal = map[string]*RegexpQuery{field: rq}
+	q.setLast(rq, rq.setBoost)
+	return q
+}
+
+// RegexpFlags sets the optional operators of a regexp query, ALL (default),
+// ANYSTRING, COMPLEMENT, EMPTY, INTERSECTION, INTERVAL or NONE joined by |
+func (q *QueryDsl) RegexpFlags(flags string) *QueryDsl {
+	if rq, ok := q.last.(*RegexpQuery); ok {
+		rq.Flags = flags
+	}
+	return q
+}
+
+// MaxDeterminizedStates limits the complexity of a regexp query
+func (q *QueryDsl) MaxDeterminizedStates(max int) *QueryDsl {
+	if rq, ok := q.last.(*RegexpQuery); ok {
+		rq.MaxDeterminizedStates = max
+	}
+	return q
+}
+
+// Fuzzy adds a fuzzy query, for terms within an edit distance of value.
+// See Fuzziness, PrefixLength and MaxExpansions.
+func (q *QueryDsl) Fuzzy(field string, value interface{}) *QueryDsl {
+	fq := &FuzzyQuery{Value: value}
+	q.FuzzyVal = map[string]*FuzzyQuery{field: fq}
+	q.setLast(fq, fq.setBoost)
+	return q
+}
+
+// PrefixLength sets how many leading characters of a fuzzy query must match exactly
+func (q *QueryDsl) PrefixLength(length int) *QueryDsl {
+	if fq, ok := q.last.(*FuzzyQuery); ok {
+		fq.PrefixLength = length
+	}
+	return q
+}
+
+// RangeQuery adds a range query on the field, pass nil for the bounds that are
+// not needed.  See Format and TimeZone for date fields.
+//
+//	Query().RangeQuery("age", 10, nil, 20, nil)
+func (q *QueryDsl) RangeQuery(field string, gte, gt, lte, lt interface{}) *QueryDsl {
+	rq := &RangeQuery{Gte: gte, Gt: gt, Lte: lte, Lt: lt}
+	if q.RangeVal == nil {
+		q.RangeVal = make(map[string]*RangeQuery)
+	}
+	q.RangeVal[field] = rq
+	q.setLast(rq, rq.setBoost)
+	return q
+}
+
+// Format sets the date format of the bounds of a range query
+func (q *QueryDsl) Format(format string) *QueryDsl {
+	if rq, ok := q.last.(*RangeQuery); ok {
+		rq.Format = format
+	}
+	return q
+}
+
+// TimeZone sets the time zone of the date bounds of a range query, "+01:00"
+// or "Europe/Paris"
+func (q *QueryDsl) TimeZone(timeZone string) *QueryDsl {
+	if rq, ok := q.last.(*RangeQuery); ok {
+		rq.TimeZone = timeZone
+	}
+	return q
+}
+
+// Exists matches docs that have a value for the field
+func (q *QueryDsl) Exists(field string) *QueryDsl {
+	q.ExistsVal = &propertyPathMarker{Field: field}
+	q.setLast(nil, nil)
+	return q
+}
+
+// Ids adds an ids query, matching docs by their _id
+func (q *QueryDsl) Ids(ids ...interface{}) *QueryDsl {
+	return q.IdsByTypes(nil, ids...)
+}
+
+// IdsByTypes adds an ids query for docs of the given types
+func (q *QueryDsl) IdsByTypes(types []string, ids ...interface{}) *QueryDsl {
+	q.IdsVal = &IdsQuery{Type: types, Values: ids}
+	q.setLast(nil, q.IdsVal.setBoost)
 	return q
 }
 
 // FunctionScore sets functions to use to score the documents.
+// Legacy, use FunctionScoreQuery instead
 // http://www.elastic.co/guide/en/elasticsearch/reference/1.x/query-dsl-function-score-query.html
 func (q *QueryDsl) FunctionScore(mode string, functions ...map[string]interface{}) *QueryDsl {
 	q.QueryEmbed.FunctionScore = map[string]interface{}{
 		"functions":  functions,
 		"score_mode": mode,
 	}
+	q.QueryEmbed.FunctionScoreVal = nil
+	q.setLast(nil, nil)
+	return q
+}
+
+// FunctionScoreQuery sets a function_score query built with FunctionScore()
+//
+//	Query().FunctionScoreQuery(
+//		FunctionScore().Query(Query().Match("title", "elasticsearch")).
+//			Add(GaussFunction("date", "now", "10d").Decay(0.5)).
+//			BoostMode("multiply"),
+//	)
+func (q *QueryDsl) FunctionScoreQuery(fs *FunctionScoreQuery) *QueryDsl {
+	q.QueryEmbed.FunctionScoreVal = fs
+	q.QueryEmbed.FunctionScore = nil
+	q.setLast(nil, func(boost float64) { fs.Boost(boost) })
+	return q
+}
+
+// Bool sets a bool query, matching docs for a boolean combination of other queries
+//
+//	Query().Bool(
+//		Bool().Must(Query().Term("user", "kimchy")).
+//			Should(Query().Search("elasticsearch"), Query().Search("lucene")).
+//			MinimumShouldMatch("1"),
+//	)
+func (q *QueryDsl) Bool(b *BoolQuery) *QueryDsl {
+	q.QueryEmbed.BoolVal = b
+	q.setLast(nil, func(boost float64) { b.Boost(boost) })
+	return q
+}
+
+// DisMax sets a dis_max query
+//
+//	Query().DisMax(
+//		DisMax(Query().Match("title", "brown fox"), Query().Match("body", "brown fox")).
+//			TieBreaker(0.3),
+//	)
+func (q *QueryDsl) DisMax(d *DisMaxQuery) *QueryDsl {
+	q.QueryEmbed.DisMaxVal = d
+	q.setLast(nil, func(boost float64) { d.Boost(boost) })
+	return q
+}
+
+// ConstantScore sets a constant_score query
+//
+//	Query().ConstantScore(ConstantScore(Filter().Term("user", "kimchy")).Boost(1.2))
+func (q *QueryDsl) ConstantScore(c *ConstantScoreQuery) *QueryDsl {
+	q.QueryEmbed.ConstantScoreVal = c
+	q.setLast(nil, func(boost float64) { c.Boost(boost) })
+	return q
+}
+
+// Boosting sets a boosting query
+//
+//	Query().Boosting(Boosting(Query().Match("name", "apple"), Query().Term("type", "fruit"), 0.2))
+func (q *QueryDsl) Boosting(b *BoostingQuery) *QueryDsl {
+	q.QueryEmbed.BoostingVal = b
+	q.setLast(nil, func(boost float64) { b.Boost(boost) })
+	return q
+}
+
+// Indices sets an indices query
+//
+//	Query().Indices(Indices(Query().Term("tag", "wow"), "index1", "index2").NoMatch("none"))
+func (q *QueryDsl) Indices(i *IndicesQuery) *QueryDsl {
+	q.QueryEmbed.IndicesVal = i
+	q.setLast(nil, nil)
+	return q
+}
+
+// Nested matches docs having nested objects at path that match the query,
+// which must use the full path of the nested fields.  See ScoreMode and
+// InnerHits.
+//
+//	Query().Nested("comments", Query().Match("comments.text", "great")).
+//		ScoreMode("max")
+func (q *QueryDsl) Nested(path string, query *QueryDsl) *QueryDsl {
+	jq := &JoinQuery{Path: path, QueryVal: query}
+	q.NestedVal = jq
+	q.setLast(jq, jq.setBoost)
+	return q
+}
+
+// HasChild matches parent docs having children of the type that match the
+// query.  See ScoreMode, MinChildren, MaxChildren and InnerHits.
+func (q *QueryDsl) HasChild(_type string, query *QueryDsl) *QueryDsl {
+	jq := &JoinQuery{Type: _type, QueryVal: query}
+	q.HasChildVal = jq
+	q.setLast(jq, jq.setBoost)
+	return q
+}
+
+// HasParent matches child docs whose parent of the type matches the query.
+// See ScoreMode and InnerHits.
+func (q *QueryDsl) HasParent(parentType string, query *QueryDsl) *QueryDsl {
+	jq := &JoinQuery{ParentType: parentType, QueryVal: query}
+	q.HasParentVal = jq
+	q.setLast(jq, jq.setBoost)
+	return q
+}
+
+// ParentId matches the children of the type whose parent has the id
+func (q *QueryDsl) ParentId(_type, id string) *QueryDsl {
+	q.ParentIdVal = &ParentIdQuery{Type: _type, Id: id}
+	q.setLast(nil, nil)
+	return q
+}
+
+// ScoreMode sets how the scores of matching nested objects or children are
+// combined into the score of the hit, avg, sum, min, max or none, has_parent
+// takes score or none
+func (q *QueryDsl) ScoreMode(mode string) *QueryDsl {
+	if jq, ok := q.last.(*JoinQuery); ok {
+		jq.ScoreMode = mode
+	}
+	return q
+}
+
+// MinChildren sets the min number of matching children of a has_child query
+func (q *QueryDsl) MinChildren(min int) *QueryDsl {
+	if jq, ok := q.last.(*JoinQuery); ok {
+		jq.MinChildren = min
+	}
+	return q
+}
+
+// MaxChildren sets the max number of matching children of a has_child query
+func (q *QueryDsl) MaxChildren(max int) *QueryDsl {
+	if jq, ok := q.last.(*JoinQuery); ok {
+		jq.MaxChildren = max
+	}
+	return q
+}
+
+// InnerHits returns the matching nested objects or children/parents of a
+// nested, has_child or has_parent query on each Hit, see Hit.InnerHits
+func (q *QueryDsl) InnerHits(innerHits *InnerHitsDsl) *QueryDsl {
+	if jq, ok := q.last.(*JoinQuery); ok {
+		jq.InnerHitsVal = innerHits
+	}
 	return q
 }
 
@@ -135,12 +491,27 @@ func (q *QueryDsl) Search(searchFor string) *QueryDsl {
 	qs := NewQueryString("", "")
 	q.QueryEmbed.Qs = &qs
 	q.QueryEmbed.Qs.Query = searchFor
+	q.setLast(nil, nil)
+	return q
+}
+
+// SimpleQueryString sets a simple_query_string query, which never fails on
+// bad syntax so unlike Search it is safe for user input
+//
+//	Query().SimpleQueryString(
+//		SimpleQueryString(`"fried eggs" +(eggplant | potato) -frittata`).
+//			Fields("title^5", "body").DefaultOperator("and"),
+//	)
+func (q *QueryDsl) SimpleQueryString(s *SimpleQueryStringQuery) *QueryDsl {
+	q.QueryEmbed.SimpleQsVal = s
+	q.setLast(nil, func(boost float64) { s.Boost(boost) })
 	return q
 }
 
 // Querystring operations
 func (q *QueryDsl) Qs(qs *QueryString) *QueryDsl {
 	q.QueryEmbed.Qs = qs
+	q.setLast(nil, nil)
 	return q
 }
 
@@ -169,6 +540,7 @@ func (q *QueryDsl) Fields(fields, search, exists, missing string) *QueryDsl {
 	}
 	q.QueryEmbed.Qs.Exists = exists
 	q.QueryEmbed.Qs.Missing = missing
+	q.setLast(nil, nil)
 	return q
 }
 
@@ -181,9 +553,311 @@ func (q *QueryDsl) Filter(f *FilterOp) *QueryDsl {
 // MultiMatch allows searching against multiple fields.
 func (q *QueryDsl) MultiMatch(s string, fields []string) *QueryDsl {
 	q.QueryEmbed.MultiMatch = &MultiMatch{Query: s, Fields: fields}
+	q.setLast(nil, nil)
 	return q
 }
 
+// Bool creates a blank bool query, add clauses with Must, Should, MustNot and Filter
+// http://www.elastic.co/guide/en/elasticsearch/reference/current/query-dsl-bool-query.html
+func Bool() *BoolQuery {
+	return &BoolQuery{}
+}
+
+type BoolQuery struct {
+	MustVal           []*QueryDsl `json:"must,omitempty"`
+	ShouldVal         []*QueryDsl `json:"should,omitempty"`
+	MustNotVal        []*QueryDsl `json:"must_not,omitempty"`
+	FilterVal         []*QueryDsl `json:"filter,omitempty"`
+	MinShouldMatchVal string      `json:"minimum_should_match,omitempty"`
+	BoostVal          float64     `json:"boost,omitempty"`
+}
+
+// Must adds clauses that all have to match, and contribute to the score
+func (b *BoolQuery) Must(queries ...*QueryDsl) *BoolQuery {
+	b.MustVal = append(b.MustVal, queries...)
+	return b
+}
+
+// Should adds clauses of which at least MinimumShouldMatch have to match, or
+// none if there are other clauses
+func (b *BoolQuery) Should(queries ...*QueryDsl) *BoolQuery {
+	b.ShouldVal = append(b.ShouldVal, queries...)
+	return b
+}
+
+// MustNot adds clauses that must not match
+func (b *BoolQuery) MustNot(queries ...*QueryDsl) *BoolQuery {
+	b.MustNotVal = append(b.MustNotVal, queries...)
+	return b
+}
+
+// Filter adds clauses that have to match but don't contribute to the score
+func (b *BoolQuery) Filter(queries ...*QueryDsl) *BoolQuery {
+	b.FilterVal = append(b.FilterVal, queries...)
+	return b
+}
+
+// MinimumShouldMatch is a number ("2", "-1") or percentage ("75%") of should
+// clauses that need to match
+func (b *BoolQuery) MinimumShouldMatch(min string) *BoolQuery {
+	b.MinShouldMatchVal = min
+	return b
+}
+
+func (b *BoolQuery) Boost(boost float64) *BoolQuery {
+	b.BoostVal = boost
+	return b
+}
+
+// SimpleQueryString creates a simple_query_string query searching for text,
+// it supports + | - " * ( ) ~ operators and ignores invalid parts
+// http://www.elastic.co/guide/en/elasticsearch/reference/1.x/query-dsl-simple-query-string-query.html
+func SimpleQueryString(text string) *SimpleQueryStringQuery {
+	return &SimpleQueryStringQuery{Query: text}
+}
+
+type SimpleQueryStringQuery struct {
+	Query              string   `json:"query"`
+	FieldsVal          []string `json:"fields,omitempty"`
+	DefaultOperatorVal string   `json:"default_operator,omitempty"`
+	FlagsVal           string   `json:"flags,omitempty"`
+	AnalyzerVal        string   `json:"analyzer,omitempty"`
+	LenientVal         bool     `json:"lenient,omitempty"`
+	MinShouldMatchVal  string   `json:"minimum_should_match,omitempty"`
+	BoostVal           float64  `json:"boost,omitempty"`
+}
+
+// Fields sets the fields searched, with optional boosts "title^5", defaults
+// to _all
+func (s *SimpleQueryStringQuery) Fields(fields ...string) *SimpleQueryStringQuery {
+	s.FieldsVal = append(s.FieldsVal, fields...)
+	return s
+}
+
+// DefaultOperator sets how terms are combined, "or" (default) or "and"
+func (s *SimpleQueryStringQuery) DefaultOperator(op string) *SimpleQueryStringQuery {
+	s.DefaultOperatorVal = op
+	return s
+}
+
+// Flags limits the enabled operators, ALL (default), NONE, AND, OR, NOT,
+// PREFIX, PHRASE, PRECEDENCE, ESCAPE, WHITESPACE, FUZZY, NEAR and SLOP
+func (s *SimpleQueryStringQuery) Flags(flags ...string) *SimpleQueryStringQuery {
+	s.FlagsVal = strings.Join(flags, "|")
+	return s
+}
+
+func (s *SimpleQueryStringQuery) Analyzer(analyzer string) *SimpleQueryStringQuery {
+	s.AnalyzerVal = analyzer
+	return s
+}
+
+// Lenient ignores format failures, such as text in a numeric field
+func (s *SimpleQueryStringQuery) Lenient(lenient bool) *SimpleQueryStringQuery {
+	s.LenientVal = lenient
+	return s
+}
+
+// MinimumShouldMatch is a number ("2", "-1") or percentage ("75%") of terms
+// that need to match
+func (s *SimpleQueryStringQuery) MinimumShouldMatch(min string) *SimpleQueryStringQuery {
+	s.MinShouldMatchVal = min
+	return s
+}
+
+func (s *SimpleQueryStringQuery) Boost(boost float64) *SimpleQueryStringQuery {
+	s.BoostVal = boost
+	return s
+}
+
+// Match adds a match query, the text is analyzed and never parsed as lucene
+// syntax, so it is safe for user input.  Chain the match options after it,
+// like all options they apply to the last query set and are ignored if it
+// doesn't have them
+//
+//	Query().Match("message", "this is a test").Operator("and").Fuzziness("AUTO")
+//
+// http://www.elastic.co/guide/en/elasticsearch/reference/current/query-dsl-match-query.html
+func (q *QueryDsl) Match(field, text string) *QueryDsl {
+	mq := &MatchQuery{Query: text}
+	q.MatchVal = map[string]*MatchQuery{field: mq}
+	q.MatchPhraseVal, q.MatchPhrasePrefixVal = nil, nil
+	q.setLast(mq, mq.setBoost)
+	return q
+}
+
+// MatchPhrase adds a match_phrase query, the terms have to appear in order,
+// within Slop positions of each other
+func (q *QueryDsl) MatchPhrase(field, text string) *QueryDsl {
+	mq := &MatchQuery{Query: text}
+	q.MatchPhraseVal = map[string]*MatchQuery{field: mq}
+	q.MatchVal, q.MatchPhrasePrefixVal = nil, nil
+	q.setLast(mq, mq.setBoost)
+	return q
+}
+
+// MatchPhrasePrefix adds a match_phrase_prefix query, a phrase whose last term
+// is a prefix, for search as you type.  See MaxExpansions.
+func (q *QueryDsl) MatchPhrasePrefix(field, text string) *QueryDsl {
+	mq := &MatchQuery{Query: text}
+	q.MatchPhrasePrefixVal = map[string]*MatchQuery{field: mq}
+	q.MatchVal, q.MatchPhraseVal = nil, nil
+	q.setLast(mq, mq.setBoost)
+	return q
+}
+
+// Operator sets how the terms of a match query are combined, "or" (default) or "and"
+func (q *QueryDsl) Operator(op string) *QueryDsl {
+	if mq, ok := q.last.(*MatchQuery); ok {
+		mq.Operator = op
+	}
+	return q
+}
+
+// Fuzziness sets the allowed edit distance of a match or fuzzy query, "0",
+// "1", "2" or "AUTO"
+func (q *QueryDsl) Fuzziness(fuzziness string) *QueryDsl {
+	switch last := q.last.(type) {
+	case *MatchQuery:
+		last.Fuzziness = fuzziness
+	case *FuzzyQuery:
+		last.Fuzziness = fuzziness
+	}
+	return q
+}
+
+// Analyzer sets the analyzer for the text of a match query, defaults to the
+// search analyzer of the field
+func (q *QueryDsl) Analyzer(analyzer string) *QueryDsl {
+	if mq, ok := q.last.(*MatchQuery); ok {
+		mq.Analyzer = analyzer
+	}
+	return q
+}
+
+// Slop sets how far apart the terms of a match_phrase(_prefix) query may be
+func (q *QueryDsl) Slop(slop int) *QueryDsl {
+	if mq, ok := q.last.(*MatchQuery); ok {
+		mq.Slop = slop
+	}
+	return q
+}
+
+// ZeroTermsQuery sets what a match query matches when the analyzer removes all
+// terms (eg. only stop words), "none" (default) or "all"
+func (q *QueryDsl) ZeroTermsQuery(zeroTerms string) *QueryDsl {
+	if mq, ok := q.last.(*MatchQuery); ok {
+		mq.ZeroTermsQuery = zeroTerms
+	}
+	return q
+}
+
+// CutoffFrequency sets the frequency (a fraction, or an absolute count if >= 1)
+// above which terms of a match query are only used for scoring
+func (q *QueryDsl) CutoffFrequency(freq float64) *QueryDsl {
+	if mq, ok := q.last.(*MatchQuery); ok {
+		mq.CutoffFrequency = freq
+	}
+	return q
+}
+
+// MaxExpansions sets how many terms the prefix of a match_phrase_prefix
+// query, or a fuzzy query, expands to
+func (q *QueryDsl) MaxExpansions(max int) *QueryDsl {
+	switch last := q.last.(type) {
+	case *MatchQuery:
+		last.MaxExpansions = max
+	case *FuzzyQuery:
+		last.MaxExpansions = max
+	}
+	return q
+}
+
+// Boost sets the boost of the last query set
+func (q *QueryDsl) Boost(boost float64) *QueryDsl {
+	if q.boost != nil {
+		q.boost(boost)
+	}
+	return q
+}
+
+// MatchQuery holds the options of match, match_phrase and match_phrase_prefix
+// queries, set them with the QueryDsl functions following Match()
+type MatchQuery struct {
+	Query           string  `json:"query"`
+	Operator        string  `json:"operator,omitempty"`
+	Fuzziness       string  `json:"fuzziness,omitempty"`
+	Analyzer        string  `json:"analyzer,omitempty"`
+	Slop            int     `json:"slop,omitempty"`
+	ZeroTermsQuery  string  `json:"zero_terms_query,omitempty"`
+	CutoffFrequency float64 `json:"cutoff_frequency,omitempty"`
+	MaxExpansions   int     `json:"max_expansions,omitempty"`
+	Boost           float64 `json:"boost,omitempty"`
+}
+
+func (m *MatchQuery) setBoost(boost float64) {
+	m.Boost = boost
+}
+
+// TermQuery holds the value of prefix and wildcard queries
+type TermQuery struct {
+	Value interface{} `json:"value"`
+	Boost float64     `json:"boost,omitempty"`
+}
+
+func (t *TermQuery) setBoost(boost float64) {
+	t.Boost = boost
+}
+
+type RegexpQuery struct {
+	Value                 string  `json:"value"`
+	Flags                 string  `json:"flags,omitempty"`
+	MaxDeterminizedStates int     `json:"max_determinized_states,omitempty"`
+	Boost                 float64 `json:"boost,omitempty"`
+}
+
+func (r *RegexpQuery) setBoost(boost float64) {
+	r.Boost = boost
+}
+
+type FuzzyQuery struct {
+	Value         interface{} `json:"value"`
+	Fuzziness     string      `json:"fuzziness,omitempty"`
+	PrefixLength  int         `json:"prefix_length,omitempty"`
+	MaxExpansions int         `json:"max_expansions,omitempty"`
+	Boost         float64     `json:"boost,omitempty"`
+}
+
+func (f *FuzzyQuery) setBoost(boost float64) {
+	f.Boost = boost
+}
+
+// RangeQuery matches values between the bounds, like the RangeFilter but
+// with a format for dates and a boost
+type RangeQuery struct {
+	Gte      interface{} `json:"gte,omitempty"`
+	Gt       interface{} `json:"gt,omitempty"`
+	Lte      interface{} `json:"lte,omitempty"`
+	Lt       interface{} `json:"lt,omitempty"`
+	Format   string      `json:"format,omitempty"`
+	TimeZone string      `json:"time_zone,omitempty"`
+	Boost    float64     `json:"boost,omitempty"`
+}
+
+func (r *RangeQuery) setBoost(boost float64) {
+	r.Boost = boost
+}
+
+type IdsQuery struct {
+	Type   []string      `json:"type,omitempty"`
+	Values []interface{} `json:"values"`
+	Boost  float64       `json:"boost,omitempty"`
+}
+
+func (i *IdsQuery) setBoost(boost float64) {
+	i.Boost = boost
+}
+
 type MultiMatch struct {
 	Query  string   `json:"query"`
 	Fields []string `json:"fields"`
@@ -0,0 +1,475 @@
+// Copyright 2013 Matthew Baird
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//     http://www.apache.org/licenses/LICENSE-2.0
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package elastigo
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/bmizerany/assert"
+)
+
+func TestBoolQuery(t *testing.T) {
+	qry := Query().Bool(
+		Bool().
+			Must(Query().Term("user", "kimchy")).
+			Should(Query().Search("elasticsearch"), Query().Bool(Bool().MustNot(Query().All()))).
+			MustNot(Query().Term("state", "deleted")).
+			Filter(Query().Term("tag", "tech")).
+			MinimumShouldMatch("1").
+			Boost(1.5),
+	)
+
+	marshaled, err := json.Marshal(qry)
+	if err != nil {
+		t.Errorf("Failed to marshal bool query: %s", err.Error())
+		return
+	}
+
+	assertJsonMatch(
+		t,
+		marshaled,
+		[]byte(`
+			{
+				"bool": {
+					"must": [
+						{ "term": { "user": "kimchy" } }
+					],
+					"should": [
+						{ "query_string": { "query": "elasticsearch" } },
+						{ "bool": { "must_not": [ { "match_all": {} } ] } }
+					],
+					"must_not": [
+						{ "term": { "state": "deleted" } }
+					],
+					"filter": [
+						{ "term": { "tag": "tech" } }
+					],
+					"minimum_should_match": "1",
+					"boost": 1.5
+				}
+			}
+		`),
+	)
+
+	// a filter on the outer query wraps the bool in a filtered query
+	qry = Query().Bool(Bool().Must(Query().Term("user", "kimchy"))).Filter(Filter().Exists("age"))
+	marshaled, err = json.Marshal(qry)
+	if err != nil {
+		t.Errorf("Failed to marshal filtered bool query: %s", err.Error())
+		return
+	}
+
+	assertJsonMatch(
+		t,
+		marshaled,
+		[]byte(`
+			{
+				"filtered": {
+					"query": {
+						"bool": { "must": [ { "term": { "user": "kimchy" } } ] }
+					},
+					"filter": { "exists": { "field": "age" } }
+				}
+			}
+		`),
+	)
+}
+
+func TestMatchQueries(t *testing.T) {
+	qry := Query().Bool(
+		Bool().
+			Must(Query().Match("message", "a: http://b/c").Operator("and").Fuzziness("AUTO").
+				Analyzer("standard").ZeroTermsQuery("all").CutoffFrequency(0.001).Boost(2)).
+			Should(Query().MatchPhrase("title", "quick brown fox").Slop(2)).
+			Should(Query().MatchPhrasePrefix("title", "quick br").MaxExpansions(10)),
+	)
+
+	marshaled, err := json.Marshal(qry)
+	if err != nil {
+		t.Errorf("Failed to marshal match queries: %s", err.Error())
+		return
+	}
+
+	assertJsonMatch(
+		t,
+		marshaled,
+		[]byte(`
+			{
+				"bool": {
+					"must": [
+						{
+							"match": {
+								"message": {
+									"query": "a: http://b/c",
+									"operator": "and",
+									"fuzziness": "AUTO",
+									"analyzer": "standard",
+									"zero_terms_query": "all",
+									"cutoff_frequency": 0.001,
+									"boost": 2
+								}
+							}
+						}
+					],
+					"should": [
+						{ "match_phrase": { "title": { "query": "quick brown fox", "slop": 2 } } },
+						{ "match_phrase_prefix": { "title": { "query": "quick br", "max_expansions": 10 } } }
+					]
+				}
+			}
+		`),
+	)
+
+	// the last match type set wins
+	marshaled, _ = json.Marshal(Query().Match("title", "fox").MatchPhrase("title", "brown fox"))
+	assertJsonMatch(t, marshaled, []byte(`{"match_phrase": {"title": {"query": "brown fox"}}}`))
+
+	// options only apply to the last query set, and are ignored by queries
+	// that don't have them
+	marshaled, _ = json.Marshal(Query().Match("title", "fox").MultiMatch("fox", []string{"body"}).Operator("and"))
+	assertJsonMatch(t, marshaled, []byte(`{"match": {"title": {"query": "fox"}}, "multi_match": {"query": "fox", "fields": ["body"]}}`))
+	marshaled, _ = json.Marshal(Query().Match("title", "fox").Fuzzy("user", "ki").Fuzziness("2").MaxExpansions(5).Analyzer("standard"))
+	assertJsonMatch(t, marshaled, []byte(`{
+		"match": {"title": {"query": "fox"}},
+		"fuzzy": {"user": {"value": "ki", "fuzziness": "2", "max_expansions": 5}}
+	}`))
+	marshaled, _ = json.Marshal(Query().Match("title", "fox").Term("user", "kimchy").Operator("and").Slop(2))
+	assertJsonMatch(t, marshaled, []byte(`{"match": {"title": {"query": "fox"}}, "term": {"user": "kimchy"}}`))
+}
+
+func TestTermQueryTerms(t *testing.T) {
+	// string terms stay in the Terms field, others are sent along with them
+	qry := Query().Term("user", "kimchy").Term("age", 22).Term("tag", "go").Boost(2)
+	assert.Equal(t, map[string]string{"user": "kimchy"}, qry.Terms)
+	qry.Terms["name"] = "shay"
+	marshaled, _ := json.Marshal(qry)
+	assertJsonMatch(t, marshaled, []byte(`{"term": {"user": "kimchy", "name": "shay", "age": 22, "tag": {"value": "go", "boost": 2}}}`))
+
+	qry = &QueryDsl{}
+	qry.Terms = map[string]string{"user": "kimchy"}
+	marshaled, _ = json.Marshal(qry)
+	assertJsonMatch(t, marshaled, []byte(`{"term": {"user": "kimchy"}}`))
+}
+
+func TestTermLevelQueries(t *testing.T) {
+	qry := Query().Bool(
+		Bool().
+			Must(
+				Query().Term("age", 22).Boost(2),
+				Query().Term("active", true),
+				Query().TermsQuery("user", "kimchy", "elasticsearch").Boost(1.5),
+				Query().RangeQuery("born", "01/01/2012", nil, "2013", nil).Format("dd/MM/yyyy||yyyy").TimeZone("+01:00"),
+				Query().RangeQuery("score", nil, 1.5, nil, 3).Boost(2),
+				Query().Exists("user"),
+			).
+			Should(
+				Query().Prefix("user", "ki").Boost(2),
+				Query().Wildcard("user", "ki*y"),
+				Query().Regexp("name.first", "s.*y").RegexpFlags("INTERSECTION|COMPLEMENT").MaxDeterminizedStates(20000),
+				Query().Fuzzy("user", "ki").Fuzziness("2").PrefixLength(1).MaxExpansions(100).Boost(1.2),
+				Query().Ids("1", 4, "100"),
+				Query().IdsByTypes([]string{"my_type"}, "1").Boost(3),
+			),
+	)
+
+	marshaled, err := json.Marshal(qry)
+	if err != nil {
+		t.Errorf("Failed to marshal term level queries: %s", err.Error())
+		return
+	}
+
+	assertJsonMatch(
+		t,
+		marshaled,
+		[]byte(`
+			{
+				"bool": {
+					"must": [
+						{ "term": { "age": { "value": 22, "boost": 2 } } },
+						{ "term": { "active": true } },
+						{ "terms": { "user": ["kimchy", "elasticsearch"], "boost": 1.5 } },
+						{ "range": { "born": { "gte": "01/01/2012", "lte": "2013", "format": "dd/MM/yyyy||yyyy", "time_zone": "+01:00" } } },
+						{ "range": { "score": { "gt": 1.5, "lt": 3, "boost": 2 } } },
+						{ "exists": { "field": "user" } }
+					],
+					"should": [
+						{ "prefix": { "user": { "value": "ki", "boost": 2 } } },
+						{ "wildcard": { "user": { "value": "ki*y" } } },
+						{ "regexp": { "name.first": { "value": "s.*y", "flags": "INTERSECTION|COMPLEMENT", "max_determinized_states": 20000 } } },
+						{ "fuzzy": { "user": { "value": "ki", "fuzziness": "2", "prefix_length": 1, "max_expansions": 100, "boost": 1.2 } } },
+						{ "ids": { "values": ["1", 4, "100"] } },
+						{ "ids": { "type": ["my_type"], "values": ["1"], "boost": 3 } }
+					]
+				}
+			}
+		`),
+	)
+}
+
+func TestFunctionScoreQuery(t *testing.T) {
+	qry := Query().FunctionScoreQuery(
+		FunctionScore().
+			Query(Query().Match("title", "elasticsearch")).
+			Add(
+				WeightFunction(2).Filter(Filter().Term("featured", true)),
+				RandomScoreFunction(42),
+				FieldValueFactorFunction("popularity").Factor(1.2).Modifier("sqrt").Missing(1),
+				ScriptScoreFunction("_score * doc['likes'].value / factor", map[string]interface{}{"factor": 2}).Lang("groovy"),
+				GaussFunction("date", "now", "10d").Offset("5d").Decay(0.5).Filter(Filter().Exists("date")),
+				LinearFunction("price", 0, 20).MultiValueMode("avg"),
+				ExpFunction("location", "11,12", "2km").Weight(3),
+			).
+			ScoreMode("sum").
+			BoostMode("multiply").
+			MaxBoost(10).
+			MinScore(0.5),
+	).Boost(2)
+
+	marshaled, err := json.Marshal(qry)
+	if err != nil {
+		t.Errorf("Failed to marshal function score query: %s", err.Error())
+		return
+	}
+
+	assertJsonMatch(
+		t,
+		marshaled,
+		[]byte(`
+			{
+				"function_score": {
+					"query": { "match": { "title": { "query": "elasticsearch" } } },
+					"functions": [
+						{ "filter": { "term": { "featured": true } }, "weight": 2 },
+						{ "random_score": { "seed": 42 } },
+						{ "field_value_factor": { "field": "popularity", "factor": 1.2, "modifier": "sqrt", "missing": 1 } },
+						{ "script_score": { "script": "_score * doc['likes'].value / factor", "lang": "groovy", "params": { "factor": 2 } } },
+						{
+							"filter": { "exists": { "field": "date" } },
+							"gauss": { "date": { "origin": "now", "scale": "10d", "offset": "5d", "decay": 0.5 } }
+						},
+						{ "linear": { "price": { "origin": 0, "scale": 20 }, "multi_value_mode": "avg" } },
+						{ "exp": { "location": { "origin": "11,12", "scale": "2km" } }, "weight": 3 }
+					],
+					"score_mode": "sum",
+					"boost_mode": "multiply",
+					"max_boost": 10,
+					"min_score": 0.5,
+					"boost": 2
+				}
+			}
+		`),
+	)
+
+	// the legacy map is still a map, and replaces the typed builder
+	legacy := Query().FunctionScoreQuery(FunctionScore().BoostMode("sum")).
+		FunctionScore("sum", map[string]interface{}{"weight": 2})
+	assert.T(t, legacy.FunctionScoreVal == nil, "Should have replaced the typed function_score")
+	assert.Equal(t, "sum", legacy.QueryEmbed.FunctionScore["score_mode"])
+	marshaled, _ = json.Marshal(legacy)
+	assertJsonMatch(t, marshaled, []byte(`{"function_score": {"functions": [{"weight": 2}], "score_mode": "sum"}}`))
+}
+
+func TestJoinQueries(t *testing.T) {
+	qry := Query().Bool(
+		Bool().Must(
+			Query().Nested("comments", Query().Match("comments.text", "great")).
+				ScoreMode("max").
+				InnerHits(InnerHits().Size(2).Sort(Sort("comments.date").Desc()).Source("comments.text")),
+			Query().HasChild("answer", Query().Term("accepted", true)).
+				ScoreMode("sum").MinChildren(1).MaxChildren(10).
+				InnerHits(InnerHits().Name("accepted")),
+			Query().HasParent("question", Query().Term("tag", "go")).Boost(2),
+			Query().ParentId("answer", "1"),
+		),
+	)
+
+	marshaled, err := json.Marshal(qry)
+	if err != nil {
+		t.Errorf("Failed to marshal join queries: %s", err.Error())
+		return
+	}
+
+	assertJsonMatch(
+		t,
+		marshaled,
+		[]byte(`
+			{
+				"bool": {
+					"must": [
+						{
+							"nested": {
+								"path": "comments",
+								"query": { "match": { "comments.text": { "query": "great" } } },
+								"score_mode": "max",
+								"inner_hits": {
+									"size": 2,
+									"sort": [ { "comments.date": "desc" } ],
+									"_source": [ "comments.text" ]
+								}
+							}
+						},
+						{
+							"has_child": {
+								"type": "answer",
+								"query": { "term": { "accepted": true } },
+								"score_mode": "sum",
+								"min_children": 1,
+								"max_children": 10,
+								"inner_hits": { "name": "accepted" }
+							}
+						},
+						{
+							"has_parent": {
+								"parent_type": "question",
+								"query": { "term": { "tag": "go" } },
+								"boost": 2
+							}
+						},
+						{ "parent_id": { "type": "answer", "id": "1" } }
+					]
+				}
+			}
+		`),
+	)
+}
+
+func TestCompoundQueries(t *testing.T) {
+	qry := Query().FunctionScoreQuery(
+		FunctionScore().Query(
+			Query().Bool(
+				Bool().
+					Must(Query().DisMax(
+						DisMax(Query().Match("title", "brown fox"), Query().Match("body", "brown fox")).
+							TieBreaker(0.3),
+					)).
+					Should(
+						Query().ConstantScore(ConstantScore(Filter().Term("user", "kimchy"))).Boost(1.2),
+						Query().Boosting(Boosting(Query().Match("name", "apple"), Query().Term("type", "fruit"), 0.2)),
+					).
+					Filter(Query().Indices(
+						Indices(Query().Term("tag", "wow"), "index1", "index2").
+							NoMatchQuery(Query().Term("tag", "kow")),
+					)),
+			),
+		).Add(WeightFunction(2)),
+	)
+
+	marshaled, err := json.Marshal(qry)
+	if err != nil {
+		t.Errorf("Failed to marshal compound queries: %s", err.Error())
+		return
+	}
+
+	assertJsonMatch(
+		t,
+		marshaled,
+		[]byte(`
+			{
+				"function_score": {
+					"query": {
+						"bool": {
+							"must": [
+								{
+									"dis_max": {
+										"queries": [
+											{ "match": { "title": { "query": "brown fox" } } },
+											{ "match": { "body": { "query": "brown fox" } } }
+										],
+										"tie_breaker": 0.3
+									}
+								}
+							],
+							"should": [
+								{ "constant_score": { "filter": { "term": { "user": "kimchy" } }, "boost": 1.2 } },
+								{
+									"boosting": {
+										"positive": { "match": { "name": { "query": "apple" } } },
+										"negative": { "term": { "type": "fruit" } },
+										"negative_boost": 0.2
+									}
+								}
+							],
+							"filter": [
+								{
+									"indices": {
+										"indices": [ "index1", "index2" ],
+										"query": { "term": { "tag": "wow" } },
+										"no_match_query": { "term": { "tag": "kow" } }
+									}
+								}
+							]
+						}
+					},
+					"functions": [ { "weight": 2 } ]
+				}
+			}
+		`),
+	)
+
+	for i := 0; i < 10; i++ {
+		again, _ := json.Marshal(qry)
+		if string(again) != string(marshaled) {
+			t.Errorf("Serialization is not deterministic:\n%s\n%s", marshaled, again)
+			return
+		}
+	}
+}
+
+func TestSimpleQueryString(t *testing.T) {
+	qry := Query().SimpleQueryString(
+		SimpleQueryString(`"fried eggs" +(eggplant | potato) -frittata`).
+			Fields("title^5", "body").
+			DefaultOperator("and").
+			Flags("OR", "AND", "PREFIX").
+			Analyzer("snowball").
+			Lenient(true).
+			MinimumShouldMatch("75%"),
+	).Boost(2)
+
+	marshaled, err := json.Marshal(qry)
+	if err != nil {
+		t.Errorf("Failed to marshal simple query string: %s", err.Error())
+		return
+	}
+
+	assertJsonMatch(
+		t,
+		marshaled,
+		[]byte(`
+			{
+				"simple_query_string": {
+					"query": "\"fried eggs\" +(eggplant | potato) -frittata",
+					"fields": [ "title^5", "body" ],
+					"default_operator": "and",
+					"flags": "OR|AND|PREFIX",
+					"analyzer": "snowball",
+					"lenient": true,
+					"minimum_should_match": "75%",
+					"boost": 2
+				}
+			}
+		`),
+	)
+
+	marshaled, err = json.Marshal(Search("github").SimpleSearch(`bad "input (`))
+	if err != nil {
+		t.Errorf("Failed to marshal simple search: %s", err.Error())
+		return
+	}
+
+	assertJsonMatch(
+		t,
+		marshaled,
+		[]byte(`{ "query": { "simple_query_string": { "query": "bad \"input (" } } }`),
+	)
+}
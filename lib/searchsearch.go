@@ -50,9 +50,14 @@ type SearchDsl struct {
 	FilterVal     *FilterOp                `json:"filter,omitempty"`
 	AggregatesVal map[string]*AggregateDsl `json:"aggregations,omitempty"`
 	HighlightVal  *HighlightDsl            `json:"highlight,omitempty"`
+	SuggestVal    map[string]*SuggestDsl   `json:"suggest,omitempty"`
+	SliceVal      *SliceDsl                `json:"slice,omitempty"`
 }
 
 func (s *SearchDsl) Bytes(conn *Conn) ([]byte, error) {
+	if err := validateBucketsPaths(s.AggregatesVal); err != nil {
+		return nil, err
+	}
 	return conn.DoCommand("POST", s.url(), s.args, s)
 }
 
@@ -109,6 +114,13 @@ func (s *SearchDsl) Search(srch string) *SearchDsl {
 	return s
 }
 
+// SimpleSearch is like Search but uses a simple_query_string search, so
+// syntax errors in user input don't fail the search
+func (s *SearchDsl) SimpleSearch(text string) *SearchDsl {
+	s.QueryVal = Query().SimpleQueryString(SimpleQueryString(text))
+	return s
+}
+
 func (s *SearchDsl) Size(size string) *SearchDsl {
 	s.args["size"] = size
 	return s
@@ -157,6 +169,17 @@ func (s *SearchDsl) Aggregates(aggs ...*AggregateDsl) *SearchDsl {
 	return s
 }
 
+// Suggest adds suggesters, their results are in SearchResult.Suggestions
+func (s *SearchDsl) Suggest(suggests ...*SuggestDsl) *SearchDsl {
+	if len(s.SuggestVal) == 0 {
+		s.SuggestVal = make(map[string]*SuggestDsl)
+	}
+	for _, suggest := range suggests {
+		s.SuggestVal[suggest.Name] = suggest
+	}
+	return s
+}
+
 func (s *SearchDsl) Query(q *QueryDsl) *SearchDsl {
 	s.QueryVal = q
 	return s
@@ -198,11 +221,38 @@ func (s *SearchDsl) Scroll(duration string) *SearchDsl {
 	return s
 }
 
+// Slice limits a scroll to slice id of max, each slice can be scrolled
+// concurrently, see SlicedScroll
+func (s *SearchDsl) Slice(id, max int) *SearchDsl {
+	s.SliceVal = &SliceDsl{Id: id, Max: max}
+	return s
+}
+
+// SliceDsl splits a scroll by the field, _uid by default
+type SliceDsl struct {
+	Field string `json:"field,omitempty"`
+	Id    int    `json:"id"`
+	Max   int    `json:"max"`
+}
+
 func (s *SearchDsl) SearchType(searchType string) *SearchDsl {
 	s.args["search_type"] = searchType
 	return s
 }
 
+// Preference sets which shard copies are searched, _local, _primary or a
+// custom string to stick a user to the same copies
+func (s *SearchDsl) Preference(preference string) *SearchDsl {
+	s.args["preference"] = preference
+	return s
+}
+
+// Routing limits the search to the shards of the routing values
+func (s *SearchDsl) Routing(routing ...string) *SearchDsl {
+	s.args["routing"] = strings.Join(routing, ",")
+	return s
+}
+
 func (s *SearchDsl) Highlight(highlight *HighlightDsl) *SearchDsl {
 	s.HighlightVal = highlight
 	return s
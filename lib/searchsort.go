@@ -26,30 +26,198 @@ func Sort(field string) *SortDsl {
 	return &SortDsl{Name: field}
 }
 
+// GeoDistanceSort sorts on a raw _geo_distance payload, see
+// GeoDistanceSortFrom for a typed one
 func GeoDistanceSort(field interface{}) *SortDsl {
 	return &SortDsl{GeoDistance: field}
 }
 
+// GeoDistanceSortFrom sorts by the distance of the geo_point field from the
+// closest of the origins, see Unit and DistanceType
+//
+//	GeoDistanceSortFrom("pin.location", GeoLocation{Latitude: 40, Longitude: -70}).Unit("km")
+func GeoDistanceSortFrom(field string, origins ...GeoPoint) *SortDsl {
+	return &SortDsl{GeoField: field, GeoOrigins: origins}
+}
+
+// ScriptSort sorts by the result of the script, scriptType is the type of
+// the result, number or string
+//
+//	ScriptSort("doc['price'].value * factor", "number").Params(map[string]interface{}{"factor": 1.1})
+func ScriptSort(script, scriptType string) *SortDsl {
+	return &SortDsl{ScriptVal: script, ScriptTypeVal: scriptType}
+}
+
 type SortBody []interface{}
 type SortDsl struct {
 	Name        string
 	IsDesc      bool
 	GeoDistance interface{}
+
+	// set with Desc, Asc or Order, only sent when there are other options
+	// unless it is desc
+	OrderVal        string
+	MissingVal      interface{}
+	ModeVal         string
+	NestedPathVal   string
+	NestedFilterVal *FilterOp
+	UnmappedTypeVal string
+
+	GeoField        string
+	GeoOrigins      []GeoPoint
+	UnitVal         string
+	DistanceTypeVal string
+
+	ScriptVal     string
+	ScriptTypeVal string
+	ParamsVal     map[string]interface{}
+	LangVal       string
 }
 
 func (s *SortDsl) Desc() *SortDsl {
 	s.IsDesc = true
+	s.OrderVal = "desc"
 	return s
 }
 func (s *SortDsl) Asc() *SortDsl {
 	s.IsDesc = false
+	s.OrderVal = "asc"
+	return s
+}
+
+// Order sets the sort order, asc or desc
+func (s *SortDsl) Order(order string) *SortDsl {
+	if order == "desc" {
+		return s.Desc()
+	}
+	return s.Asc()
+}
+
+// Missing sets where docs without a value for the field go, _last (default),
+// _first or a value used in place of the missing one
+func (s *SortDsl) Missing(missing interface{}) *SortDsl {
+	s.MissingVal = missing
+	return s
+}
+
+// Mode sets which value of a multi valued field is sorted on, min, max, avg
+// or sum
+func (s *SortDsl) Mode(mode string) *SortDsl {
+	s.ModeVal = mode
+	return s
+}
+
+// NestedPath sorts on a field of the nested objects at path
+func (s *SortDsl) NestedPath(path string) *SortDsl {
+	s.NestedPathVal = path
+	return s
+}
+
+// NestedFilter limits the nested objects whose values are sorted on
+func (s *SortDsl) NestedFilter(filter *FilterOp) *SortDsl {
+	s.NestedFilterVal = filter
+	return s
+}
+
+// UnmappedType sets the type of the field for indices that don't map it,
+// so that they sort as if it had no values instead of failing the search
+func (s *SortDsl) UnmappedType(_type string) *SortDsl {
+	s.UnmappedTypeVal = _type
 	return s
 }
 
+// Unit sets the unit of the distances of a geo distance sort, km (default),
+// mi, m, ...
+func (s *SortDsl) Unit(unit string) *SortDsl {
+	s.UnitVal = unit
+	return s
+}
+
+// DistanceType sets how the distances of a geo distance sort are computed,
+// sloppy_arc (default), arc or plane
+func (s *SortDsl) DistanceType(distanceType string) *SortDsl {
+	s.DistanceTypeVal = distanceType
+	return s
+}
+
+// Params sets the params of a script sort
+func (s *SortDsl) Params(params map[string]interface{}) *SortDsl {
+	s.ParamsVal = params
+	return s
+}
+
+// Lang sets the language of a script sort
+func (s *SortDsl) Lang(lang string) *SortDsl {
+	s.LangVal = lang
+	return s
+}
+
+type sortOptions struct {
+	Order        string      `json:"order,omitempty"`
+	Missing      interface{} `json:"missing,omitempty"`
+	Mode         string      `json:"mode,omitempty"`
+	NestedPath   string      `json:"nested_path,omitempty"`
+	NestedFilter *FilterOp   `json:"nested_filter,omitempty"`
+	UnmappedType string      `json:"unmapped_type,omitempty"`
+}
+
+type scriptSort struct {
+	Script string                 `json:"script"`
+	Type   string                 `json:"type"`
+	Params map[string]interface{} `json:"params,omitempty"`
+	Lang   string                 `json:"lang,omitempty"`
+	sortOptions
+}
+
+func (s *SortDsl) options() sortOptions {
+	order := s.OrderVal
+	if order == "" && s.IsDesc {
+		order = "desc"
+	}
+	return sortOptions{
+		Order:        order,
+		Missing:      s.MissingVal,
+		Mode:         s.ModeVal,
+		NestedPath:   s.NestedPathVal,
+		NestedFilter: s.NestedFilterVal,
+		UnmappedType: s.UnmappedTypeVal,
+	}
+}
+
 func (s *SortDsl) MarshalJSON() ([]byte, error) {
 	if s.GeoDistance != nil {
 		return json.Marshal(map[string]interface{}{"_geo_distance": s.GeoDistance})
 	}
+	if s.GeoField != "" {
+		geo := map[string]interface{}{s.GeoField: s.GeoOrigins}
+		if len(s.GeoOrigins) == 1 {
+			geo[s.GeoField] = s.GeoOrigins[0]
+		}
+		opts := s.options()
+		for key, val := range map[string]string{
+			"order":         opts.Order,
+			"mode":          opts.Mode,
+			"unit":          s.UnitVal,
+			"distance_type": s.DistanceTypeVal,
+			"nested_path":   opts.NestedPath,
+		} {
+			if val != "" {
+				geo[key] = val
+			}
+		}
+		if opts.NestedFilter != nil {
+			geo["nested_filter"] = opts.NestedFilter
+		}
+		return json.Marshal(map[string]interface{}{"_geo_distance": geo})
+	}
+	if s.ScriptVal != "" {
+		script := scriptSort{Script: s.ScriptVal, Type: s.ScriptTypeVal, Params: s.ParamsVal, Lang: s.LangVal, sortOptions: s.options()}
+		return json.Marshal(map[string]interface{}{"_script": script})
+	}
+	if opts := s.options(); opts.Missing != nil || opts.Mode != "" || opts.NestedPath != "" ||
+		opts.NestedFilter != nil || opts.UnmappedType != "" {
+		return json.Marshal(map[string]interface{}{s.Name: opts})
+	}
 	if s.IsDesc {
 		return json.Marshal(map[string]string{s.Name: "desc"})
 	}
@@ -0,0 +1,83 @@
+// Copyright 2013 Matthew Baird
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//     http://www.apache.org/licenses/LICENSE-2.0
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package elastigo
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestSortDsl(t *testing.T) {
+	sorts := []*SortDsl{
+		Sort("age"),
+		Sort("dob").Desc(),
+		Sort("price").Asc().Missing("_first").Mode("avg").UnmappedType("double"),
+		Sort("offer.price").Order("desc").Mode("min").NestedPath("offer").
+			NestedFilter(Filter().Term("offer.color", "blue")),
+		ScriptSort("doc['price'].value * factor", "number").
+			Params(map[string]interface{}{"factor": 1.1}).Lang("groovy").Desc(),
+		GeoDistanceSortFrom("pin.location", GeoLocation{Latitude: 40, Longitude: -70}).
+			Unit("km").DistanceType("plane"),
+		GeoDistanceSortFrom("pin.location",
+			GeoLocation{Latitude: 40, Longitude: -70}.AsArray(), GeoHash("drm3btev3e86")).
+			Mode("max").Desc(),
+	}
+
+	marshaled, err := json.Marshal(sorts)
+	if err != nil {
+		t.Errorf("Failed to marshal sorts: %s", err.Error())
+		return
+	}
+
+	assertJsonMatch(
+		t,
+		marshaled,
+		[]byte(`
+			[
+				"age",
+				{ "dob": "desc" },
+				{ "price": { "order": "asc", "missing": "_first", "mode": "avg", "unmapped_type": "double" } },
+				{
+					"offer.price": {
+						"order": "desc",
+						"mode": "min",
+						"nested_path": "offer",
+						"nested_filter": { "term": { "offer.color": "blue" } }
+					}
+				},
+				{
+					"_script": {
+						"script": "doc['price'].value * factor",
+						"type": "number",
+						"params": { "factor": 1.1 },
+						"lang": "groovy",
+						"order": "desc"
+					}
+				},
+				{
+					"_geo_distance": {
+						"pin.location": { "lat": 40, "lon": -70 },
+						"unit": "km",
+						"distance_type": "plane"
+					}
+				},
+				{
+					"_geo_distance": {
+						"pin.location": [ [ -70, 40 ], "drm3btev3e86" ],
+						"order": "desc",
+						"mode": "max"
+					}
+				}
+			]
+		`),
+	)
+}
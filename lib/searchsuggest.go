@@ -0,0 +1,368 @@
+// Copyright 2013 Matthew Baird
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//     http://www.apache.org/licenses/LICENSE-2.0
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package elastigo
+
+import "encoding/json"
+
+// Suggest creates a named suggester, pick the kind with Term, Phrase or
+// Completion and add it to a search with SearchDsl.Suggest, the results are
+// in SearchResult.Suggestions under the name.  Size, ShardSize and Analyzer
+// may come before the kind, the options of one kind are ignored by the
+// others.  A map[string]*SuggestDsl of suggesters is also a valid
+// Conn.Suggest body.
+//
+//	Search("github").Suggest(
+//		Suggest("did_you_mean").Text("elasticsaerch").Phrase("title").
+//			Generators(DirectGenerator("title").SuggestMode("always")),
+//	)
+//
+// http://www.elastic.co/guide/en/elasticsearch/reference/1.x/search-suggesters.html
+func Suggest(name string) *SuggestDsl {
+	return &SuggestDsl{Name: name}
+}
+
+type SuggestDsl struct {
+	Name     string
+	TextVal  string
+	TypeName string
+	// a *TermSuggester, *PhraseSuggester or *CompletionSuggester
+	Type interface{}
+	// the shared options set before the kind of suggester
+	pending SuggesterOptions
+}
+
+// The options shared by all the suggesters
+type SuggesterOptions struct {
+	Field     string `json:"field"`
+	Analyzer  string `json:"analyzer,omitempty"`
+	Size      int    `json:"size,omitempty"`
+	ShardSize int    `json:"shard_size,omitempty"`
+}
+
+type TermSuggester struct {
+	SuggesterOptions
+	SuggestMode   string `json:"suggest_mode,omitempty"`
+	Sort          string `json:"sort,omitempty"`
+	MaxEdits      int    `json:"max_edits,omitempty"`
+	PrefixLength  int    `json:"prefix_length,omitempty"`
+	MinWordLength int    `json:"min_word_length,omitempty"`
+}
+
+type PhraseSuggester struct {
+	SuggesterOptions
+	GramSize                int                   `json:"gram_size,omitempty"`
+	Confidence              *float64              `json:"confidence,omitempty"`
+	MaxErrors               float64               `json:"max_errors,omitempty"`
+	RealWordErrorLikelihood float64               `json:"real_word_error_likelihood,omitempty"`
+	Separator               string                `json:"separator,omitempty"`
+	Highlight               *SuggestHighlight     `json:"highlight,omitempty"`
+	Collate                 *SuggestCollate       `json:"collate,omitempty"`
+	DirectGenerators        []*DirectGeneratorDsl `json:"direct_generator,omitempty"`
+}
+
+type SuggestHighlight struct {
+	PreTag  string `json:"pre_tag"`
+	PostTag string `json:"post_tag"`
+}
+
+// SuggestCollate checks each phrase suggestion against a query, the
+// suggestion is in the {{suggestion}} template variable
+type SuggestCollate struct {
+	Query  interface{}            `json:"query"`
+	Params map[string]interface{} `json:"params,omitempty"`
+	// keep suggestions that don't match, flagged with CollateMatch false
+	Prune bool `json:"prune,omitempty"`
+}
+
+type CompletionSuggester struct {
+	SuggesterOptions
+	Fuzzy   *CompletionFuzzy       `json:"fuzzy,omitempty"`
+	Context map[string]interface{} `json:"context,omitempty"`
+}
+
+type CompletionFuzzy struct {
+	Fuzziness      interface{} `json:"fuzziness,omitempty"`
+	Transpositions *bool       `json:"transpositions,omitempty"`
+	MinLength      int         `json:"min_length,omitempty"`
+	PrefixLength   int         `json:"prefix_length,omitempty"`
+	UnicodeAware   bool        `json:"unicode_aware,omitempty"`
+}
+
+// Text sets the text suggestions are made for
+func (s *SuggestDsl) Text(text string) *SuggestDsl {
+	s.TextVal = text
+	return s
+}
+
+// Term suggests corrections for each term of the text
+func (s *SuggestDsl) Term(field string) *SuggestDsl {
+	s.Type = &TermSuggester{SuggesterOptions: s.sharedOptions(field)}
+	s.TypeName = "term"
+	return s
+}
+
+// Phrase suggests corrections of the whole text, using a shingle field
+func (s *SuggestDsl) Phrase(field string) *SuggestDsl {
+	s.Type = &PhraseSuggester{SuggesterOptions: s.sharedOptions(field)}
+	s.TypeName = "phrase"
+	return s
+}
+
+// Completion suggests completions of the text from a completion field
+func (s *SuggestDsl) Completion(field string) *SuggestDsl {
+	s.Type = &CompletionSuggester{SuggesterOptions: s.sharedOptions(field)}
+	s.TypeName = "completion"
+	return s
+}
+
+// The shared options set so far, for a suggester on the field
+func (s *SuggestDsl) sharedOptions(field string) SuggesterOptions {
+	opts := *s.options()
+	opts.Field = field
+	return opts
+}
+
+func (s *SuggestDsl) options() *SuggesterOptions {
+	switch t := s.Type.(type) {
+	case *TermSuggester:
+		return &t.SuggesterOptions
+	case *PhraseSuggester:
+		return &t.SuggesterOptions
+	case *CompletionSuggester:
+		return &t.SuggesterOptions
+	}
+	return &s.pending
+}
+
+// Size sets the max number of options returned per suggestion
+func (s *SuggestDsl) Size(size int) *SuggestDsl {
+	s.options().Size = size
+	return s
+}
+
+func (s *SuggestDsl) ShardSize(size int) *SuggestDsl {
+	s.options().ShardSize = size
+	return s
+}
+
+// Analyzer sets the analyzer of the text, defaults to the field's
+func (s *SuggestDsl) Analyzer(analyzer string) *SuggestDsl {
+	s.options().Analyzer = analyzer
+	return s
+}
+
+// SuggestMode sets which terms get suggestions, missing (default), popular
+// or always, for term suggesters
+func (s *SuggestDsl) SuggestMode(mode string) *SuggestDsl {
+	if t, ok := s.Type.(*TermSuggester); ok {
+		t.SuggestMode = mode
+	}
+	return s
+}
+
+// Sort sets the order of term suggestions, score (default) or frequency
+func (s *SuggestDsl) Sort(sort string) *SuggestDsl {
+	if t, ok := s.Type.(*TermSuggester); ok {
+		t.Sort = sort
+	}
+	return s
+}
+
+// MaxEdits sets the max edit distance of term suggestions, 1 or 2
+func (s *SuggestDsl) MaxEdits(max int) *SuggestDsl {
+	if t, ok := s.Type.(*TermSuggester); ok {
+		t.MaxEdits = max
+	}
+	return s
+}
+
+// PrefixLength sets how many leading characters of a term must match
+func (s *SuggestDsl) PrefixLength(length int) *SuggestDsl {
+	if t, ok := s.Type.(*TermSuggester); ok {
+		t.PrefixLength = length
+	}
+	return s
+}
+
+func (s *SuggestDsl) MinWordLength(length int) *SuggestDsl {
+	if t, ok := s.Type.(*TermSuggester); ok {
+		t.MinWordLength = length
+	}
+	return s
+}
+
+// GramSize sets the max shingle size of the field of a phrase suggester
+func (s *SuggestDsl) GramSize(size int) *SuggestDsl {
+	if t, ok := s.Type.(*PhraseSuggester); ok {
+		t.GramSize = size
+	}
+	return s
+}
+
+// Confidence sets how much better than the text a phrase suggestion must
+// score to be returned, 0 returns the top suggestions regardless
+func (s *SuggestDsl) Confidence(confidence float64) *SuggestDsl {
+	if t, ok := s.Type.(*PhraseSuggester); ok {
+		t.Confidence = &confidence
+	}
+	return s
+}
+
+// MaxErrors sets the max number, or fraction below 1, of misspelled terms
+// in a phrase suggestion
+func (s *SuggestDsl) MaxErrors(max float64) *SuggestDsl {
+	if t, ok := s.Type.(*PhraseSuggester); ok {
+		t.MaxErrors = max
+	}
+	return s
+}
+
+func (s *SuggestDsl) RealWordErrorLikelihood(likelihood float64) *SuggestDsl {
+	if t, ok := s.Type.(*PhraseSuggester); ok {
+		t.RealWordErrorLikelihood = likelihood
+	}
+	return s
+}
+
+// Highlight wraps the changed terms of phrase suggestions, returned in
+// SuggestionOption.Highlighted
+func (s *SuggestDsl) Highlight(preTag, postTag string) *SuggestDsl {
+	if t, ok := s.Type.(*PhraseSuggester); ok {
+		t.Highlight = &SuggestHighlight{PreTag: preTag, PostTag: postTag}
+	}
+	return s
+}
+
+/**
+ * Collate drops phrase suggestions for which the query template finds no
+ * docs, or flags them when prune is set
+ *
+ * Suggest("fix").Text("noble prize").Phrase("title.trigram").Collate(
+ *   map[string]interface{}{"match": map[string]string{"title": "{{suggestion}}"}}, nil, true)
+ */
+func (s *SuggestDsl) Collate(query interface{}, params map[string]interface{}, prune bool) *SuggestDsl {
+	if t, ok := s.Type.(*PhraseSuggester); ok {
+		t.Collate = &SuggestCollate{Query: query, Params: params, Prune: prune}
+	}
+	return s
+}
+
+// Generators adds the candidate generators of a phrase suggester
+func (s *SuggestDsl) Generators(generators ...*DirectGeneratorDsl) *SuggestDsl {
+	if t, ok := s.Type.(*PhraseSuggester); ok {
+		t.DirectGenerators = append(t.DirectGenerators, generators...)
+	}
+	return s
+}
+
+// Fuzzy makes a completion suggester match text within the edit distance,
+// 1 by default, "AUTO" or a number
+func (s *SuggestDsl) Fuzzy(fuzziness interface{}) *SuggestDsl {
+	if t, ok := s.Type.(*CompletionSuggester); ok {
+		t.Fuzzy = &CompletionFuzzy{Fuzziness: fuzziness}
+	}
+	return s
+}
+
+// FuzzyOptions sets all the fuzzy options of a completion suggester
+func (s *SuggestDsl) FuzzyOptions(fuzzy *CompletionFuzzy) *SuggestDsl {
+	if t, ok := s.Type.(*CompletionSuggester); ok {
+		t.Fuzzy = fuzzy
+	}
+	return s
+}
+
+// Context limits the completions to the context values, keyed by the
+// context name of the completion field mapping
+func (s *SuggestDsl) Context(name string, value interface{}) *SuggestDsl {
+	if t, ok := s.Type.(*CompletionSuggester); ok {
+		if t.Context == nil {
+			t.Context = make(map[string]interface{})
+		}
+		t.Context[name] = value
+	}
+	return s
+}
+
+func (s *SuggestDsl) MarshalJSON() ([]byte, error) {
+	m := make(map[string]interface{})
+	if s.TextVal != "" {
+		m["text"] = s.TextVal
+	}
+	if s.Type != nil {
+		m[s.TypeName] = s.Type
+	}
+	return json.Marshal(m)
+}
+
+// DirectGenerator creates a candidate generator of a phrase suggester,
+// generating corrections of each term from the field like a term suggester
+func DirectGenerator(field string) *DirectGeneratorDsl {
+	return &DirectGeneratorDsl{Field: field}
+}
+
+type DirectGeneratorDsl struct {
+	Field            string `json:"field"`
+	SizeVal          int    `json:"size,omitempty"`
+	SuggestModeVal   string `json:"suggest_mode,omitempty"`
+	MaxEditsVal      int    `json:"max_edits,omitempty"`
+	PrefixLengthVal  int    `json:"prefix_length,omitempty"`
+	MinWordLengthVal int    `json:"min_word_length,omitempty"`
+	PreFilterVal     string `json:"pre_filter,omitempty"`
+	PostFilterVal    string `json:"post_filter,omitempty"`
+}
+
+func (g *DirectGeneratorDsl) Size(size int) *DirectGeneratorDsl {
+	g.SizeVal = size
+	return g
+}
+
+func (g *DirectGeneratorDsl) SuggestMode(mode string) *DirectGeneratorDsl {
+	g.SuggestModeVal = mode
+	return g
+}
+
+func (g *DirectGeneratorDsl) MaxEdits(max int) *DirectGeneratorDsl {
+	g.MaxEditsVal = max
+	return g
+}
+
+func (g *DirectGeneratorDsl) PrefixLength(length int) *DirectGeneratorDsl {
+	g.PrefixLengthVal = length
+	return g
+}
+
+func (g *DirectGeneratorDsl) MinWordLength(length int) *DirectGeneratorDsl {
+	g.MinWordLengthVal = length
+	return g
+}
+
+// PreFilter sets the analyzer applied to each term before generating
+// corrections, to generate from reversed terms for example
+func (g *DirectGeneratorDsl) PreFilter(analyzer string) *DirectGeneratorDsl {
+	g.PreFilterVal = analyzer
+	return g
+}
+
+// PostFilter sets the analyzer applied to each generated correction
+func (g *DirectGeneratorDsl) PostFilter(analyzer string) *DirectGeneratorDsl {
+	g.PostFilterVal = analyzer
+	return g
+}
+
+// DecodePayload decodes the payload of a completion suggestion into v
+func (o *SuggestionOption) DecodePayload(v interface{}) error {
+	if len(o.Payload) == 0 {
+		return nil
+	}
+	return json.Unmarshal(o.Payload, v)
+}
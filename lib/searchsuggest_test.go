@@ -0,0 +1,132 @@
+// Copyright 2013 Matthew Baird
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//     http://www.apache.org/licenses/LICENSE-2.0
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package elastigo
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/bmizerany/assert"
+)
+
+func TestSuggestDsl(t *testing.T) {
+	qry := Search("github").Suggest(
+		Suggest("spelling").Text("elasticsaerch").Term("body").
+			SuggestMode("popular").Sort("frequency").MaxEdits(2).Size(3),
+		Suggest("did_you_mean").Text("noble prize").Phrase("title.trigram").
+			GramSize(3).Confidence(0).MaxErrors(2).Highlight("<em>", "</em>").
+			Collate(map[string]interface{}{"match": map[string]string{"title": "{{suggestion}}"}}, nil, true).
+			Generators(
+				DirectGenerator("title.trigram").SuggestMode("always"),
+				DirectGenerator("title.reverse").PreFilter("reverse").PostFilter("reverse"),
+			),
+		Suggest("autocomplete").Text("nir").Completion("suggest").
+			Fuzzy(2).Context("color", "red").Size(5),
+	)
+
+	marshaled, err := json.Marshal(qry)
+	if err != nil {
+		t.Errorf("Failed to marshal suggesters: %s", err.Error())
+		return
+	}
+
+	assertJsonMatch(
+		t,
+		marshaled,
+		[]byte(`
+			{
+				"suggest": {
+					"spelling": {
+						"text": "elasticsaerch",
+						"term": {
+							"field": "body",
+							"size": 3,
+							"suggest_mode": "popular",
+							"sort": "frequency",
+							"max_edits": 2
+						}
+					},
+					"did_you_mean": {
+						"text": "noble prize",
+						"phrase": {
+							"field": "title.trigram",
+							"gram_size": 3,
+							"confidence": 0,
+							"max_errors": 2,
+							"highlight": { "pre_tag": "<em>", "post_tag": "</em>" },
+							"collate": {
+								"query": { "match": { "title": "{{suggestion}}" } },
+								"prune": true
+							},
+							"direct_generator": [
+								{ "field": "title.trigram", "suggest_mode": "always" },
+								{ "field": "title.reverse", "pre_filter": "reverse", "post_filter": "reverse" }
+							]
+						}
+					},
+					"autocomplete": {
+						"text": "nir",
+						"completion": {
+							"field": "suggest",
+							"size": 5,
+							"fuzzy": { "fuzziness": 2 },
+							"context": { "color": "red" }
+						}
+					}
+				}
+			}
+		`),
+	)
+
+	// shared options set before the kind are kept
+	marshaled, _ = json.Marshal(Suggest("early").Size(4).Analyzer("simple").Term("body").ShardSize(8))
+	assertJsonMatch(t, marshaled, []byte(`{"term": {"field": "body", "size": 4, "analyzer": "simple", "shard_size": 8}}`))
+}
+
+func TestSuggestResult(t *testing.T) {
+	var out SearchResult
+	err := json.Unmarshal([]byte(`{
+		"hits": { "total": 0, "hits": [] },
+		"suggest": {
+			"did_you_mean": [
+				{
+					"text": "noble prize", "offset": 0, "length": 11,
+					"options": [
+						{ "text": "nobel prize", "highlighted": "<em>nobel</em> prize", "score": 0.4, "collate_match": true }
+					]
+				}
+			],
+			"autocomplete": [
+				{
+					"text": "nir", "offset": 0, "length": 3,
+					"options": [
+						{ "text": "Nirvana", "score": 34, "payload": { "artist_id": 2321 } }
+					]
+				}
+			]
+		}
+	}`), &out)
+	assert.Equal(t, nil, err)
+
+	phrase := out.Suggestions["did_you_mean"][0].Options[0]
+	assert.Equal(t, "nobel prize", phrase.Text)
+	assert.Equal(t, "<em>nobel</em> prize", phrase.Highlighted)
+	assert.T(t, phrase.CollateMatch != nil && *phrase.CollateMatch)
+
+	completion := out.Suggestions["autocomplete"][0]
+	assert.Equal(t, 3, completion.Length)
+	var payload struct {
+		ArtistId int `json:"artist_id"`
+	}
+	assert.Equal(t, nil, completion.Options[0].DecodePayload(&payload))
+	assert.Equal(t, 2321, payload.ArtistId)
+}